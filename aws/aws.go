@@ -9,17 +9,29 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	cloudformationTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cloudwatchTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2Types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 	"github.com/aws/aws-sdk-go-v2/service/ecr"
 	ecrTypes "github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamTypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
 	"github.com/aws/aws-sdk-go-v2/service/kms"
 	kmsTypes "github.com/aws/aws-sdk-go-v2/service/kms/types"
 	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdaTypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+	"github.com/aws/aws-sdk-go-v2/service/route53"
+	route53Types "github.com/aws/aws-sdk-go-v2/service/route53/types"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqsTypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
 	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/aws/smithy-go/ptr"
 	"os"
 	"strings"
+	"time"
 )
 
 type Aws struct {
@@ -32,6 +44,11 @@ type Aws struct {
 	cloudformationClient *cloudformation.Client
 	apiGatewayClient     *apigatewayv2.Client
 	kmsClient            *kms.Client
+	cloudwatchClient     *cloudwatch.Client
+	sqsClient            *sqs.Client
+	iamClient            *iam.Client
+	ec2Client            *ec2.Client
+	route53Client        *route53.Client
 }
 
 func New(profile string, region string) (*Aws, error) {
@@ -45,6 +62,15 @@ func New(profile string, region string) (*Aws, error) {
 	}, nil
 }
 
+func (aws *Aws) ForRegion(region string) *Aws {
+	awsConfig := aws.config.Copy()
+	awsConfig.Region = region
+
+	return &Aws{
+		config: &awsConfig,
+	}
+}
+
 func (aws *Aws) BucketExists(name *string) bool {
 	_, err := aws.s3().HeadBucket(context.Background(), &s3.HeadBucketInput{
 		Bucket: name,
@@ -83,6 +109,23 @@ func (aws *Aws) CreateAssetsBucket(name *string, region *string) error {
 	return nil
 }
 
+func (aws *Aws) GetBucketNotifications(name *string) (*s3.GetBucketNotificationConfigurationOutput, error) {
+	result, err := aws.s3().GetBucketNotificationConfiguration(context.Background(), &s3.GetBucketNotificationConfigurationInput{
+		Bucket: name,
+	})
+
+	return result, err
+}
+
+func (aws *Aws) PutBucketNotifications(name *string, configuration *s3Types.NotificationConfiguration) error {
+	_, err := aws.s3().PutBucketNotificationConfiguration(context.Background(), &s3.PutBucketNotificationConfigurationInput{
+		Bucket:                    name,
+		NotificationConfiguration: configuration,
+	})
+
+	return err
+}
+
 func (aws *Aws) UploadFileToAssetsBucket(bucketName *string, fileName *string, file *os.File) error {
 	_, err := aws.s3().PutObject(context.Background(), &s3.PutObjectInput{
 		Bucket: bucketName,
@@ -97,6 +140,17 @@ func (aws *Aws) UploadFileToAssetsBucket(bucketName *string, fileName *string, f
 	return nil
 }
 
+func (aws *Aws) UploadFileToBucket(bucketName *string, fileName *string, contentType *string, file *os.File) error {
+	_, err := aws.s3().PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket:      bucketName,
+		Key:         fileName,
+		ContentType: contentType,
+		Body:        file,
+	})
+
+	return err
+}
+
 func (aws *Aws) WalkBucketObjects(bucketName *string, walker func(output *s3.ListObjectsV2Output) error) error {
 	paginator := s3.NewListObjectsV2Paginator(aws.s3(), &s3.ListObjectsV2Input{
 		Bucket: bucketName,
@@ -232,16 +286,270 @@ func (aws *Aws) InvokeLambda(name *string, version *string, payload []byte) (*la
 	return result, err
 }
 
+func (aws *Aws) GetLambdaAlias(name *string, alias *string) (*lambda.GetAliasOutput, error) {
+	result, err := aws.lambda().GetAlias(context.Background(), &lambda.GetAliasInput{
+		FunctionName: name,
+		Name:         alias,
+	})
+
+	return result, err
+}
+
 func (aws *Aws) UpdateLambdaAlias(name *string, version *string, alias *string) (*lambda.UpdateAliasOutput, error) {
 	result, err := aws.lambda().UpdateAlias(context.Background(), &lambda.UpdateAliasInput{
 		FunctionName:    name,
 		Name:            alias,
 		FunctionVersion: version,
+		RoutingConfig:   &lambdaTypes.AliasRoutingConfiguration{},
+	})
+
+	return result, err
+}
+
+func (aws *Aws) PutProvisionedConcurrency(name *string, alias *string, concurrency int32) (*lambda.PutProvisionedConcurrencyConfigOutput, error) {
+	result, err := aws.lambda().PutProvisionedConcurrencyConfig(context.Background(), &lambda.PutProvisionedConcurrencyConfigInput{
+		FunctionName:                    name,
+		Qualifier:                       alias,
+		ProvisionedConcurrentExecutions: &concurrency,
+	})
+
+	return result, err
+}
+
+func (aws *Aws) DeleteProvisionedConcurrency(name *string, alias *string) error {
+	_, err := aws.lambda().DeleteProvisionedConcurrencyConfig(context.Background(), &lambda.DeleteProvisionedConcurrencyConfigInput{
+		FunctionName: name,
+		Qualifier:    alias,
+	})
+
+	var notFoundErr *lambdaTypes.ProvisionedConcurrencyConfigNotFoundException
+	if errors.As(err, &notFoundErr) {
+		return nil
+	}
+
+	return err
+}
+
+func (aws *Aws) ShiftLambdaAliasTraffic(name *string, alias *string, version *string, weight float64) (*lambda.UpdateAliasOutput, error) {
+	result, err := aws.lambda().UpdateAlias(context.Background(), &lambda.UpdateAliasInput{
+		FunctionName: name,
+		Name:         alias,
+		RoutingConfig: &lambdaTypes.AliasRoutingConfiguration{
+			AdditionalVersionWeights: map[string]float64{
+				*version: weight,
+			},
+		},
 	})
 
 	return result, err
 }
 
+func (aws *Aws) GetLambdaVersionMetricSum(name *string, alias *string, version *string, metric string, since time.Time) (float64, error) {
+	return aws.getMetricSum("AWS/Lambda", metric, []cloudwatchTypes.Dimension{
+		{Name: ptr.String("FunctionName"), Value: name},
+		{Name: ptr.String("Resource"), Value: ptr.String(*name + ":" + *alias)},
+		{Name: ptr.String("ExecutedVersion"), Value: version},
+	}, since)
+}
+
+func (aws *Aws) GetRuntimeVersionMetricSum(name *string, version *string, metric string, since time.Time) (float64, error) {
+	return aws.getMetricSum("Hover", metric, []cloudwatchTypes.Dimension{
+		{Name: ptr.String("FunctionName"), Value: name},
+		{Name: ptr.String("ExecutedVersion"), Value: version},
+	}, since)
+}
+
+func (aws *Aws) getMetricSum(namespace string, metric string, dimensions []cloudwatchTypes.Dimension, since time.Time) (float64, error) {
+	end := time.Now()
+	period := int32(end.Sub(since).Minutes()+1) * 60
+
+	result, err := aws.cloudwatch().GetMetricStatistics(context.Background(), &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  &namespace,
+		MetricName: &metric,
+		Dimensions: dimensions,
+		StartTime:  &since,
+		EndTime:    &end,
+		Period:     &period,
+		Statistics: []cloudwatchTypes.Statistic{cloudwatchTypes.StatisticSum},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	sum := 0.0
+
+	for _, datapoint := range result.Datapoints {
+		sum += *datapoint.Sum
+	}
+
+	return sum, nil
+}
+
+func (aws *Aws) GetQueueUrl(name *string) (*string, error) {
+	result, err := aws.sqs().GetQueueUrl(context.Background(), &sqs.GetQueueUrlInput{
+		QueueName: name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.QueueUrl, nil
+}
+
+func (aws *Aws) GetQueueAttributes(url *string) (map[string]string, error) {
+	result, err := aws.sqs().GetQueueAttributes(context.Background(), &sqs.GetQueueAttributesInput{
+		QueueUrl:       url,
+		AttributeNames: []sqsTypes.QueueAttributeName{sqsTypes.QueueAttributeNameAll},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Attributes, nil
+}
+
+func (aws *Aws) ReceiveQueueMessages(url *string, count int32, visibilityTimeout int32) ([]sqsTypes.Message, error) {
+	result, err := aws.sqs().ReceiveMessage(context.Background(), &sqs.ReceiveMessageInput{
+		QueueUrl:              url,
+		MaxNumberOfMessages:   count,
+		VisibilityTimeout:     visibilityTimeout,
+		WaitTimeSeconds:       1,
+		AttributeNames:        []sqsTypes.QueueAttributeName{sqsTypes.QueueAttributeNameAll},
+		MessageAttributeNames: []string{"All"},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Messages, nil
+}
+
+func (aws *Aws) SendQueueMessage(url *string, body *string, attributes map[string]sqsTypes.MessageAttributeValue, messageGroupId *string, deduplicationId *string) error {
+	_, err := aws.sqs().SendMessage(context.Background(), &sqs.SendMessageInput{
+		QueueUrl:               url,
+		MessageBody:            body,
+		MessageAttributes:      attributes,
+		MessageGroupId:         messageGroupId,
+		MessageDeduplicationId: deduplicationId,
+	})
+
+	return err
+}
+
+func (aws *Aws) DeleteQueueMessage(url *string, receiptHandle *string) error {
+	_, err := aws.sqs().DeleteMessage(context.Background(), &sqs.DeleteMessageInput{
+		QueueUrl:      url,
+		ReceiptHandle: receiptHandle,
+	})
+
+	return err
+}
+
+func (aws *Aws) ReleaseQueueMessage(url *string, receiptHandle *string) error {
+	_, err := aws.sqs().ChangeMessageVisibility(context.Background(), &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          url,
+		ReceiptHandle:     receiptHandle,
+		VisibilityTimeout: 0,
+	})
+
+	return err
+}
+
+func (aws *Aws) PurgeQueue(url *string) error {
+	_, err := aws.sqs().PurgeQueue(context.Background(), &sqs.PurgeQueueInput{
+		QueueUrl: url,
+	})
+
+	return err
+}
+
+func (aws *Aws) GetQueueMetricLatest(name *string, metric string) (float64, error) {
+	end := time.Now()
+	start := end.Add(-5 * time.Minute)
+
+	result, err := aws.cloudwatch().GetMetricStatistics(context.Background(), &cloudwatch.GetMetricStatisticsInput{
+		Namespace:  ptr.String("AWS/SQS"),
+		MetricName: ptr.String(metric),
+		Dimensions: []cloudwatchTypes.Dimension{
+			{Name: ptr.String("QueueName"), Value: name},
+		},
+		StartTime:  &start,
+		EndTime:    &end,
+		Period:     ptr.Int32(60),
+		Statistics: []cloudwatchTypes.Statistic{cloudwatchTypes.StatisticMaximum},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var latest *cloudwatchTypes.Datapoint
+
+	for i, datapoint := range result.Datapoints {
+		if latest == nil || datapoint.Timestamp.After(*latest.Timestamp) {
+			latest = &result.Datapoints[i]
+		}
+	}
+
+	if latest == nil {
+		return 0, nil
+	}
+
+	return *latest.Maximum, nil
+}
+
+func (aws *Aws) GetDeniedActions(principalArn *string, actions []string, resourceArn *string) ([]string, error) {
+	result, err := aws.iam().SimulatePrincipalPolicy(context.Background(), &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: principalArn,
+		ActionNames:     actions,
+		ResourceArns:    []string{*resourceArn},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var deniedActions []string
+
+	for _, evaluation := range result.EvaluationResults {
+		if evaluation.EvalDecision != iamTypes.PolicyEvaluationDecisionTypeAllowed {
+			deniedActions = append(deniedActions, *evaluation.EvalActionName)
+		}
+	}
+
+	return deniedActions, nil
+}
+
+func (aws *Aws) GetSubnets(subnetIds []string) ([]ec2Types.Subnet, error) {
+	result, err := aws.ec2().DescribeSubnets(context.Background(), &ec2.DescribeSubnetsInput{
+		SubnetIds: subnetIds,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.Subnets, nil
+}
+
+func (aws *Aws) GetRouteTables(vpcId *string) ([]ec2Types.RouteTable, error) {
+	var routeTables []ec2Types.RouteTable
+
+	paginator := ec2.NewDescribeRouteTablesPaginator(aws.ec2(), &ec2.DescribeRouteTablesInput{
+		Filters: []ec2Types.Filter{
+			{Name: ptr.String("vpc-id"), Values: []string{*vpcId}},
+		},
+	})
+
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(context.Background())
+		if err != nil {
+			return nil, err
+		}
+
+		routeTables = append(routeTables, output.RouteTables...)
+	}
+
+	return routeTables, nil
+}
+
 func (aws *Aws) GetStack(name *string) (cloudformationTypes.Stack, error) {
 	result, err := aws.cloudformation().DescribeStacks(context.Background(), &cloudformation.DescribeStacksInput{
 		StackName: name,
@@ -253,6 +561,44 @@ func (aws *Aws) GetStack(name *string) (cloudformationTypes.Stack, error) {
 	return result.Stacks[0], nil
 }
 
+func (aws *Aws) GetHostedZones(name *string) ([]route53Types.HostedZone, error) {
+	result, err := aws.route53().ListHostedZonesByName(context.Background(), &route53.ListHostedZonesByNameInput{
+		DNSName: name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result.HostedZones, nil
+}
+
+func (aws *Aws) GetResourceRecordSet(hostedZoneId *string, name *string, recordType route53Types.RRType) (*route53Types.ResourceRecordSet, error) {
+	result, err := aws.route53().ListResourceRecordSets(context.Background(), &route53.ListResourceRecordSetsInput{
+		HostedZoneId:    hostedZoneId,
+		StartRecordName: name,
+		StartRecordType: recordType,
+		MaxItems:        ptr.Int32(1),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(result.ResourceRecordSets) == 0 {
+		return nil, nil
+	}
+
+	record := result.ResourceRecordSets[0]
+
+	// Route 53 returns names with a trailing dot and escapes the wildcard.
+	recordName := strings.TrimSuffix(strings.Replace(*record.Name, "\\052", "*", 1), ".")
+
+	if !strings.EqualFold(recordName, strings.TrimSuffix(*name, ".")) || record.Type != recordType {
+		return nil, nil
+	}
+
+	return &record, nil
+}
+
 func (aws *Aws) StackDoesntExist(err error) bool {
 	return strings.Contains(err.Error(), "does not exist")
 }
@@ -265,19 +611,33 @@ func (aws *Aws) DeleteStack(name *string) (*cloudformation.DeleteStackOutput, er
 	return result, err
 }
 
-func (aws *Aws) UpdateStack(name *string, template *string) (*cloudformation.UpdateStackOutput, error) {
+func (aws *Aws) UpdateStack(name *string, template *string, parameters []cloudformationTypes.Parameter) (*cloudformation.UpdateStackOutput, error) {
 	result, err := aws.cloudformation().UpdateStack(context.Background(), &cloudformation.UpdateStackInput{
 		StackName: name,
 		Capabilities: []cloudformationTypes.Capability{
 			cloudformationTypes.CapabilityCapabilityNamedIam,
 		},
 		TemplateBody: template,
+		Parameters:   parameters,
+	})
+
+	return result, err
+}
+
+func (aws *Aws) UpdateStackParameters(name *string, parameters []cloudformationTypes.Parameter) (*cloudformation.UpdateStackOutput, error) {
+	result, err := aws.cloudformation().UpdateStack(context.Background(), &cloudformation.UpdateStackInput{
+		StackName: name,
+		Capabilities: []cloudformationTypes.Capability{
+			cloudformationTypes.CapabilityCapabilityNamedIam,
+		},
+		UsePreviousTemplate: ptr.Bool(true),
+		Parameters:          parameters,
 	})
 
 	return result, err
 }
 
-func (aws *Aws) CreateStack(name *string, template *string, roleArn *string) (*cloudformation.CreateStackOutput, error) {
+func (aws *Aws) CreateStack(name *string, template *string, roleArn *string, parameters []cloudformationTypes.Parameter) (*cloudformation.CreateStackOutput, error) {
 	result, err := aws.cloudformation().CreateStack(context.Background(), &cloudformation.CreateStackInput{
 		StackName: name,
 		Capabilities: []cloudformationTypes.Capability{
@@ -285,6 +645,7 @@ func (aws *Aws) CreateStack(name *string, template *string, roleArn *string) (*c
 		},
 		TemplateBody: template,
 		RoleARN:      roleArn,
+		Parameters:   parameters,
 	})
 
 	return result, err
@@ -405,6 +766,30 @@ func (aws *Aws) apiGateway() *apigatewayv2.Client {
 	return aws.apiGatewayClient
 }
 
+func (aws *Aws) cloudwatch() *cloudwatch.Client {
+	if aws.cloudwatchClient == nil {
+		aws.cloudwatchClient = cloudwatch.NewFromConfig(*aws.config)
+	}
+
+	return aws.cloudwatchClient
+}
+
+func (aws *Aws) sqs() *sqs.Client {
+	if aws.sqsClient == nil {
+		aws.sqsClient = sqs.NewFromConfig(*aws.config)
+	}
+
+	return aws.sqsClient
+}
+
+func (aws *Aws) iam() *iam.Client {
+	if aws.iamClient == nil {
+		aws.iamClient = iam.NewFromConfig(*aws.config)
+	}
+
+	return aws.iamClient
+}
+
 func (aws *Aws) kms() *kms.Client {
 	if aws.kmsClient == nil {
 		aws.kmsClient = kms.NewFromConfig(*aws.config)
@@ -412,3 +797,19 @@ func (aws *Aws) kms() *kms.Client {
 
 	return aws.kmsClient
 }
+
+func (aws *Aws) ec2() *ec2.Client {
+	if aws.ec2Client == nil {
+		aws.ec2Client = ec2.NewFromConfig(*aws.config)
+	}
+
+	return aws.ec2Client
+}
+
+func (aws *Aws) route53() *route53.Client {
+	if aws.route53Client == nil {
+		aws.route53Client = route53.NewFromConfig(*aws.config)
+	}
+
+	return aws.route53Client
+}
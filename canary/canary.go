@@ -0,0 +1,229 @@
+package canary
+
+import (
+	"fmt"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/pterm/pterm"
+	"hover/aws"
+	"hover/utils"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const alias = "live"
+
+type Deployment struct {
+	FunctionName string
+	Version      string
+}
+
+func ParseSteps(value string) ([]float64, error) {
+	var steps []float64
+
+	for _, step := range strings.Split(value, ",") {
+		percentage, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(step), "%"), 64)
+		if err != nil || percentage <= 0 || percentage > 100 {
+			return nil, fmt.Errorf("invalid canary step `%s`. Steps must be percentages between 0%% and 100%%", step)
+		}
+
+		if len(steps) > 0 && percentage/100 <= steps[len(steps)-1] {
+			return nil, fmt.Errorf("canary steps must be in increasing order")
+		}
+
+		steps = append(steps, percentage/100)
+	}
+
+	return steps, nil
+}
+
+// A last step below 100% holds the canary, which is no longer watched after its first interval.
+func Start(deployments []Deployment, steps []float64, interval time.Duration, errorThreshold float64, awsClient *aws.Aws) error {
+	startedAt := time.Now()
+
+	for i, weight := range steps {
+		if weight == 1 {
+			return promote(deployments, awsClient)
+		}
+
+		utils.PrintStep(fmt.Sprintf("Shifting %s of the traffic to the new version", formatWeight(weight)))
+
+		for _, deployment := range deployments {
+			_, err := awsClient.ShiftLambdaAliasTraffic(&deployment.FunctionName, ptr.String(alias), &deployment.Version, weight)
+			if err != nil {
+				return fmt.Errorf("unable to shift traffic of the %s lambda. Error: %w", deployment.FunctionName, err)
+			}
+		}
+
+		if i < len(steps)-1 {
+			fmt.Printf("Waiting %s before the next step\n", interval)
+		} else {
+			fmt.Printf("Watching the error rate for %s\n", interval)
+		}
+
+		err := watchErrorRates(deployments, interval, errorThreshold, startedAt, awsClient)
+		if err != nil {
+			return err
+		}
+	}
+
+	utils.PrintInfo("The canary is holding. Run \"hover promote\" to finish the deployment or \"hover abort\" to roll it back.")
+
+	return nil
+}
+
+func GetDeployments(functionNames []string, awsClient *aws.Aws) ([]Deployment, error) {
+	var deployments []Deployment
+
+	for _, functionName := range functionNames {
+		result, err := awsClient.GetLambdaAlias(ptr.String(functionName), ptr.String(alias))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the alias of the %s lambda. Error: %w", functionName, err)
+		}
+
+		if result.RoutingConfig == nil {
+			continue
+		}
+
+		for version := range result.RoutingConfig.AdditionalVersionWeights {
+			deployments = append(deployments, Deployment{
+				FunctionName: functionName,
+				Version:      version,
+			})
+		}
+	}
+
+	return deployments, nil
+}
+
+func Promote(functionNames []string, awsClient *aws.Aws) error {
+	deployments, err := GetDeployments(functionNames, awsClient)
+	if err != nil {
+		return err
+	}
+
+	if len(deployments) == 0 {
+		return fmt.Errorf("there is no canary to promote")
+	}
+
+	return promote(deployments, awsClient)
+}
+
+func Abort(functionNames []string, awsClient *aws.Aws) error {
+	deployments, err := GetDeployments(functionNames, awsClient)
+	if err != nil {
+		return err
+	}
+
+	if len(deployments) == 0 {
+		return fmt.Errorf("there is no canary to abort")
+	}
+
+	return abort(deployments, awsClient)
+}
+
+func promote(deployments []Deployment, awsClient *aws.Aws) error {
+	utils.PrintStep("Shifting all traffic to the new version")
+
+	for _, deployment := range deployments {
+		_, err := awsClient.UpdateLambdaAlias(&deployment.FunctionName, &deployment.Version, ptr.String(alias))
+		if err != nil {
+			return fmt.Errorf("unable to promote the %s lambda. Error: %w", deployment.FunctionName, err)
+		}
+	}
+
+	return nil
+}
+
+func abort(deployments []Deployment, awsClient *aws.Aws) error {
+	utils.PrintStep("Shifting all traffic back to the previous version")
+
+	for _, deployment := range deployments {
+		result, err := awsClient.GetLambdaAlias(&deployment.FunctionName, ptr.String(alias))
+		if err != nil {
+			return fmt.Errorf("unable to read the alias of the %s lambda. Error: %w", deployment.FunctionName, err)
+		}
+
+		_, err = awsClient.UpdateLambdaAlias(&deployment.FunctionName, result.FunctionVersion, ptr.String(alias))
+		if err != nil {
+			return fmt.Errorf("unable to abort the canary of the %s lambda. Error: %w", deployment.FunctionName, err)
+		}
+	}
+
+	return nil
+}
+
+func watchErrorRates(deployments []Deployment, interval time.Duration, errorThreshold float64, since time.Time, awsClient *aws.Aws) error {
+	deadline := time.Now().Add(interval)
+
+	for {
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return nil
+		}
+
+		if wait > time.Minute {
+			wait = time.Minute
+		}
+
+		time.Sleep(wait)
+
+		err := checkErrorRates(deployments, errorThreshold, since, awsClient)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func checkErrorRates(deployments []Deployment, errorThreshold float64, since time.Time, awsClient *aws.Aws) error {
+	if errorThreshold == 0 {
+		return nil
+	}
+
+	for _, deployment := range deployments {
+		errorRate, err := getErrorRate(deployment, since, awsClient)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Error rate of the %s lambda: %s\n", deployment.FunctionName, pterm.FgYellow.Sprintf("%.2f%%", errorRate))
+
+		if errorRate > errorThreshold {
+			abortErr := abort(deployments, awsClient)
+			if abortErr != nil {
+				return abortErr
+			}
+
+			return fmt.Errorf("the canary was aborted because the error rate of the %s lambda is above %.2f%%", deployment.FunctionName, errorThreshold)
+		}
+	}
+
+	return nil
+}
+
+func getErrorRate(deployment Deployment, since time.Time, awsClient *aws.Aws) (float64, error) {
+	invocations, err := awsClient.GetLambdaVersionMetricSum(&deployment.FunctionName, ptr.String(alias), &deployment.Version, "Invocations", since)
+	if err != nil {
+		return 0, fmt.Errorf("unable to read the invocations of the %s lambda. Error: %w", deployment.FunctionName, err)
+	}
+
+	if invocations == 0 {
+		return 0, nil
+	}
+
+	errors, err := awsClient.GetLambdaVersionMetricSum(&deployment.FunctionName, ptr.String(alias), &deployment.Version, "Errors", since)
+	if err != nil {
+		return 0, fmt.Errorf("unable to read the errors of the %s lambda. Error: %w", deployment.FunctionName, err)
+	}
+
+	serverErrors, err := awsClient.GetRuntimeVersionMetricSum(&deployment.FunctionName, &deployment.Version, "ServerErrors", since)
+	if err != nil {
+		return 0, fmt.Errorf("unable to read the server errors of the %s lambda. Error: %w", deployment.FunctionName, err)
+	}
+
+	return (errors + serverErrors) / invocations * 100, nil
+}
+
+func formatWeight(weight float64) string {
+	return strconv.FormatFloat(weight*100, 'f', -1, 64) + "%"
+}
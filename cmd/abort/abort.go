@@ -0,0 +1,54 @@
+package abort
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"hover/aws"
+	"hover/canary"
+	"hover/provisioner"
+	"hover/utils"
+	"hover/utils/manifest"
+)
+
+type options struct {
+	alias string
+}
+
+func Cmd() *cobra.Command {
+	opts := options{}
+
+	cmd := &cobra.Command{
+		Use:   "abort <ALIAS>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Send all traffic back to the previous version of a canary deployment",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.alias = args[0]
+
+			return Run(&opts)
+		},
+	}
+
+	return cmd
+}
+
+func Run(o *options) error {
+	fmt.Println()
+
+	stage, err := manifest.Get(o.alias)
+	if err != nil {
+		return err
+	}
+
+	utils.PrintStep("Aborting the canary of stage " + stage.Name)
+
+	awsClient, _ := aws.New(stage.AwsProfile, stage.Region)
+
+	err = canary.Abort(provisioner.GetHttpLambdaFunctionNames(stage), awsClient)
+	if err != nil {
+		return err
+	}
+
+	utils.PrintSuccess("The previous version is serving all traffic")
+
+	return nil
+}
@@ -4,21 +4,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 	"github.com/aws/smithy-go/ptr"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 	"hover/aws"
+	"hover/canary"
 	"hover/provisioner"
 	"hover/utils"
 	"hover/utils/manifest"
+	"io"
 	"io/fs"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 type options struct {
+	canary         string
+	canaryInterval time.Duration
 }
 
 func Cmd() *cobra.Command {
@@ -33,6 +41,9 @@ func Cmd() *cobra.Command {
 		},
 	}
 
+	cmd.Flags().StringVar(&opts.canary, "canary", "", "Shift traffic to the new HTTP version in steps, like 10% or 10%,50%,100%")
+	cmd.Flags().DurationVar(&opts.canaryInterval, "canary-interval", 5*time.Minute, "Time to wait between canary steps")
+
 	return cmd
 }
 
@@ -44,6 +55,15 @@ func Run(o *options) error {
 		return err
 	}
 
+	var canarySteps []float64
+
+	if o.canary != "" {
+		canarySteps, err = canary.ParseSteps(o.canary)
+		if err != nil {
+			return err
+		}
+	}
+
 	utils.PrintStep("Deploying stage " + stage.Name)
 
 	awsClient, _ := aws.New(stage.AwsProfile, stage.Region)
@@ -53,6 +73,11 @@ func Run(o *options) error {
 		return err
 	}
 
+	err = ensureErrorPagesExist(stage)
+	if err != nil {
+		return err
+	}
+
 	err = uploadAssets(stage, awsClient)
 	if err != nil {
 		return err
@@ -63,22 +88,67 @@ func Run(o *options) error {
 		return err
 	}
 
+	previousStack, err := provisioner.GetCloudFormationStack(stage.Name, awsClient)
+	if err != nil {
+		return err
+	}
+
 	stack, resources, err := provisioner.Provision(stage, imageUri, awsClient)
 	if err != nil {
 		return err
 	}
 
-	err = publishNewLambdaVersions(stage, resources, awsClient)
+	if stage.UsesDynamoDbCache() {
+		err = provisioner.VerifyCacheTableAccess(stage, awsClient)
+		if err != nil {
+			utils.PrintWarning(err.Error())
+		}
+	}
+
+	previousVersions, err := publishNewLambdaVersions(stage, resources, canarySteps, o.canaryInterval, awsClient)
+	if err != nil {
+		return err
+	}
+
+	err = updateProvisionedConcurrency(stage, &previousStack, awsClient)
 	if err != nil {
 		return err
 	}
 
+	if stage.HealthCheck.Path != "" {
+		if provisioner.IsInMaintenanceMode(stack) {
+			utils.PrintWarning("Skipping the health check as the stage is in maintenance mode")
+		} else if len(canarySteps) > 0 {
+			utils.PrintWarning("Skipping the health check as the canary still sends requests to the previous version")
+		} else {
+			err = runHealthCheck(stage, provisioner.GetStackOutput(stack, "CDNDomain"))
+			if err != nil {
+				rollbackErr := rollback(previousVersions, awsClient)
+				if rollbackErr != nil {
+					return fmt.Errorf("%s. The rollback failed. Error: %w", err, rollbackErr)
+				}
+
+				return err
+			}
+		}
+	}
+
+	// Scalable targets need a published version, which functions lack on their first deployment.
+	if provisioner.HasPendingConcurrencySchedules(stage, resources) {
+		utils.PrintStep("Scheduling provisioned concurrency")
+
+		stack, _, err = provisioner.Provision(stage, imageUri, awsClient)
+		if err != nil {
+			return err
+		}
+	}
+
 	table := pterm.DefaultTable
 
 	tableData := pterm.TableData{}
 
 	for _, output := range stack.Outputs {
-		if *output.OutputKey == "Signature" {
+		if output.Description == nil {
 			continue
 		}
 
@@ -91,6 +161,158 @@ func Run(o *options) error {
 
 	table.WithData(tableData).Render()
 
+	if len(stage.DomainNames()) > 0 && stage.HTTP.HostedZone == "" {
+		printDnsRecords(stage, stack)
+	}
+
+	return nil
+}
+
+func printDnsRecords(stage *manifest.Manifest, stack *types.Stack) {
+	fmt.Println()
+
+	utils.PrintInfo("Create the following DNS records to serve the stage from your domains:")
+
+	tableData := pterm.TableData{
+		{"Type", "Name", "Content"},
+	}
+
+	for _, domain := range stage.DomainNames() {
+		cdnDomain := provisioner.GetStackOutput(stack, provisioner.GetCDNDomainOutputName(stage, domain))
+
+		tableData = append(tableData, []string{"CNAME", domain, pterm.FgYellow.Sprint(cdnDomain)})
+	}
+
+	pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+
+	fmt.Println()
+
+	fmt.Println("Apex domains can't have CNAME records. Use an ALIAS or ANAME record if your DNS provider supports it.")
+	fmt.Println("Once the records are created, run \"hover domain verify\" to check they resolve to the stage.")
+}
+
+func shouldWarm(stage *manifest.Manifest) bool {
+	if stage.UsesProvisionedConcurrency() || stage.WarmContainers() == 0 {
+		return false
+	}
+
+	return !stage.HTTP.WarmWindow.Enabled() || stage.HTTP.WarmWindow.Contains(time.Now())
+}
+
+func updateProvisionedConcurrency(stage *manifest.Manifest, previousStack *types.Stack, awsClient *aws.Aws) error {
+	for functionName, concurrency := range provisioner.GetProvisionedConcurrencyChanges(stage, previousStack) {
+		if concurrency == 0 {
+			err := awsClient.DeleteProvisionedConcurrency(ptr.String(functionName), ptr.String("live"))
+			if err != nil {
+				return fmt.Errorf("unable to remove the provisioned concurrency of the %s lambda. Error: %w", functionName, err)
+			}
+
+			continue
+		}
+
+		_, err := awsClient.PutProvisionedConcurrency(ptr.String(functionName), ptr.String("live"), int32(concurrency))
+		if err != nil {
+			return fmt.Errorf("unable to set the provisioned concurrency of the %s lambda. Error: %w", functionName, err)
+		}
+
+		fmt.Println(fmt.Sprintf("Provisioned %s containers for the %s lambda", pterm.FgYellow.Sprint(concurrency), pterm.FgYellow.Sprint(functionName)))
+	}
+
+	return nil
+}
+
+func runHealthCheck(stage *manifest.Manifest, cdnDomain string) error {
+	utils.PrintStep("Running the health check")
+
+	expectedStatus := stage.HealthCheck.Status
+	if expectedStatus == 0 {
+		expectedStatus = http.StatusOK
+	}
+
+	attempts := stage.HealthCheck.Attempts
+	if attempts == 0 {
+		attempts = 3
+	}
+
+	url := "https://" + cdnDomain + "/" + strings.TrimPrefix(stage.HealthCheck.Path, "/")
+	client := http.Client{Timeout: 10 * time.Second}
+
+	var failure string
+
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(5 * time.Second)
+		}
+
+		failure = checkHealth(client, url, expectedStatus, stage.HealthCheck.Body)
+		if failure == "" {
+			fmt.Println("The health check passed")
+
+			return nil
+		}
+
+		fmt.Printf("Attempt %d of %d failed: %s\n", attempt, attempts, failure)
+	}
+
+	return fmt.Errorf("the health check failed at %s. Error: %s", url, failure)
+}
+
+func checkHealth(client http.Client, url string, expectedStatus int, expectedBody string) string {
+	response, err := client.Get(url)
+	if err != nil {
+		return err.Error()
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != expectedStatus {
+		return fmt.Sprintf("expected status %d but got %d", expectedStatus, response.StatusCode)
+	}
+
+	if expectedBody == "" {
+		return ""
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return err.Error()
+	}
+
+	if !strings.Contains(string(body), expectedBody) {
+		return fmt.Sprintf("the response doesn't contain `%s`", expectedBody)
+	}
+
+	return ""
+}
+
+func rollback(previousVersions map[string]string, awsClient *aws.Aws) error {
+	utils.PrintStep("Rolling back to the previous version")
+
+	var unversionedFunctions []string
+
+	for functionName, version := range previousVersions {
+		if version == "" || version == "$LATEST" {
+			unversionedFunctions = append(unversionedFunctions, functionName)
+
+			continue
+		}
+
+		_, err := awsClient.UpdateLambdaAlias(ptr.String(functionName), ptr.String(version), ptr.String("live"))
+		if err != nil {
+			utils.PrintWarning(err.Error())
+
+			continue
+		}
+
+		fmt.Println(fmt.Sprintf("Activated version %s of the %s lambda", pterm.FgYellow.Sprint("#"+version), pterm.FgYellow.Sprint(functionName)))
+	}
+
+	if len(unversionedFunctions) > 0 {
+		sort.Strings(unversionedFunctions)
+
+		return fmt.Errorf("the %s lambdas have no previous version to roll back to and still run the new build", strings.Join(unversionedFunctions, ", "))
+	}
+
 	return nil
 }
 
@@ -120,6 +342,21 @@ func getBuildManifest() (*manifest.Manifest, error) {
 	return &data, nil
 }
 
+func ensureErrorPagesExist(stage *manifest.Manifest) error {
+	for _, page := range []string{stage.HTTP.ErrorPage, stage.HTTP.MaintenancePage} {
+		if page == "" {
+			continue
+		}
+
+		_, err := os.Stat(filepath.Join(utils.Path.AssetsOut, page))
+		if err != nil {
+			return fmt.Errorf("cannot find the page `%s` in the assets directory. Error: %w", page, err)
+		}
+	}
+
+	return nil
+}
+
 func uploadAssets(stage *manifest.Manifest, awsClient *aws.Aws) error {
 	utils.PrintStep("Uploading assets")
 
@@ -219,7 +456,7 @@ func ensureEcrRepoExists(name string, awsClient *aws.Aws) (*string, error) {
 	return nil, err
 }
 
-func publishNewLambdaVersions(stage *manifest.Manifest, resources *cloudformation.DescribeStackResourcesOutput, awsClient *aws.Aws) error {
+func publishNewLambdaVersions(stage *manifest.Manifest, resources *cloudformation.DescribeStackResourcesOutput, canarySteps []float64, canaryInterval time.Duration, awsClient *aws.Aws) (map[string]string, error) {
 	type function struct {
 		functionType string
 		resourceName *string
@@ -271,18 +508,18 @@ func publishNewLambdaVersions(stage *manifest.Manifest, resources *cloudformatio
 	waitGroup.Wait()
 
 	if publishingHasFailed {
-		return fmt.Errorf("failed to publish new lambda version")
+		return nil, fmt.Errorf("failed to publish new lambda version")
 	}
 
 	for _, aFunction := range functions {
 		currentFunction := aFunction
 
-		if currentFunction.functionType == "http" {
+		if currentFunction.functionType == "http" && *currentFunction.functionName == provisioner.GetLambdaFunctionName(stage.Name, "http") && shouldWarm(stage) {
 			utils.PrintStep("Warming HTTP lambdas...")
 
-			waitGroup.Add(stage.HTTP.Warm)
+			waitGroup.Add(stage.WarmContainers())
 
-			for i := 0; i < stage.HTTP.Warm; i++ {
+			for i := 0; i < stage.WarmContainers(); i++ {
 
 				go func() {
 					defer waitGroup.Done()
@@ -304,13 +541,13 @@ func publishNewLambdaVersions(stage *manifest.Manifest, resources *cloudformatio
 			for _, command := range stage.DeployCommands {
 				output, exitCode, err := utils.RunCommand(currentFunction.functionName, currentFunction.version, strings.TrimPrefix(command, "php artisan"), awsClient)
 				if err != nil {
-					return err
+					return nil, err
 				}
 
 				fmt.Print(output)
 
 				if fmt.Sprint(exitCode) != "0" {
-					return fmt.Errorf("failed to run %s", command)
+					return nil, fmt.Errorf("failed to run %s", command)
 				}
 			}
 		}
@@ -318,6 +555,43 @@ func publishNewLambdaVersions(stage *manifest.Manifest, resources *cloudformatio
 
 	waitGroup.Wait()
 
+	previousVersions := map[string]string{}
+
+	for _, aFunction := range functions {
+		alias, err := awsClient.GetLambdaAlias(aFunction.functionName, ptr.String("live"))
+		if err == nil {
+			previousVersions[*aFunction.functionName] = *alias.FunctionVersion
+		}
+	}
+
+	var canaryDeployments []canary.Deployment
+
+	if len(canarySteps) > 0 {
+		var activatedFunctions []function
+
+		for _, aFunction := range functions {
+			if aFunction.functionType != "http" {
+				activatedFunctions = append(activatedFunctions, aFunction)
+
+				continue
+			}
+
+			// Traffic can only be shifted away from a published version.
+			if previousVersions[*aFunction.functionName] == "" || previousVersions[*aFunction.functionName] == "$LATEST" {
+				activatedFunctions = append(activatedFunctions, aFunction)
+
+				continue
+			}
+
+			canaryDeployments = append(canaryDeployments, canary.Deployment{
+				FunctionName: *aFunction.functionName,
+				Version:      *aFunction.version,
+			})
+		}
+
+		functions = activatedFunctions
+	}
+
 	waitGroup.Add(len(functions))
 
 	utils.PrintStep("Activating the new version...")
@@ -337,5 +611,9 @@ func publishNewLambdaVersions(stage *manifest.Manifest, resources *cloudformatio
 
 	waitGroup.Wait()
 
-	return nil
+	if len(canaryDeployments) > 0 {
+		return previousVersions, canary.Start(canaryDeployments, canarySteps, canaryInterval, stage.HTTP.Canary.ErrorThreshold, awsClient)
+	}
+
+	return previousVersions, nil
 }
@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"github.com/spf13/cobra"
+	verifyCmd "hover/cmd/domain/verify"
+)
+
+func Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "domain <command>",
+		Short: "Work with custom domains",
+	}
+
+	cmd.AddCommand(verifyCmd.Cmd())
+
+	return cmd
+}
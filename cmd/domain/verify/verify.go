@@ -0,0 +1,176 @@
+package verify
+
+import (
+	"fmt"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+	"hover/aws"
+	"hover/provisioner"
+	"hover/utils"
+	"hover/utils/manifest"
+	"net"
+	"strings"
+	"time"
+)
+
+const (
+	statusOk           = "OK"
+	statusNotPointing  = "Not pointing to the stage"
+	statusInconclusive = "Points to CloudFront, unable to confirm the distribution"
+)
+
+type options struct {
+	alias string
+}
+
+func Cmd() *cobra.Command {
+	opts := options{}
+
+	cmd := &cobra.Command{
+		Use:   "verify <ALIAS>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Check that the stage domains resolve to its CloudFront distribution",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.alias = args[0]
+
+			return Run(&opts)
+		},
+	}
+
+	return cmd
+}
+
+func Run(o *options) error {
+	fmt.Println()
+
+	stage, err := manifest.Get(o.alias)
+	if err != nil {
+		return err
+	}
+
+	if len(stage.DomainNames()) == 0 {
+		return fmt.Errorf("stage '%s' has no domains", stage.Name)
+	}
+
+	awsClient, _ := aws.New(stage.AwsProfile, stage.Region)
+
+	stack, err := awsClient.GetStack(&stage.Name)
+	if err != nil {
+		return err
+	}
+
+	utils.PrintStep("Verifying DNS records point to the stage")
+
+	hostedZoneId := ""
+
+	if stage.HTTP.HostedZone != "" {
+		hostedZoneId, err = provisioner.GetHostedZoneId(stage, awsClient)
+		if err != nil {
+			return err
+		}
+	}
+
+	tableData := pterm.TableData{}
+	failed := false
+	inconclusive := false
+
+	for _, domain := range stage.DomainNames() {
+		cdnDomain := provisioner.GetStackOutput(&stack, provisioner.GetCDNDomainOutputName(stage, domain))
+
+		var status string
+
+		if hostedZoneId != "" {
+			target, err := provisioner.GetDnsRecordTarget(hostedZoneId, domain, awsClient)
+			if err != nil {
+				return err
+			}
+
+			status = statusNotPointing
+			if strings.EqualFold(target, cdnDomain) {
+				status = statusOk
+			}
+		} else {
+			// Wildcard domains are checked by resolving an arbitrary subdomain.
+			status = resolvesTo(strings.Replace(domain, "*", "hover-verify", 1), cdnDomain)
+		}
+
+		switch status {
+		case statusOk:
+			tableData = append(tableData, []string{domain, cdnDomain, pterm.FgGreen.Sprint(status)})
+		case statusInconclusive:
+			inconclusive = true
+
+			tableData = append(tableData, []string{domain, cdnDomain, pterm.FgYellow.Sprint(status)})
+		default:
+			failed = true
+
+			tableData = append(tableData, []string{domain, cdnDomain, pterm.FgRed.Sprint(status)})
+		}
+	}
+
+	pterm.DefaultTable.WithData(tableData).Render()
+
+	fmt.Println()
+
+	if failed {
+		return fmt.Errorf("some domains don't resolve to the stage. DNS changes may take a while to propagate")
+	}
+
+	if inconclusive {
+		utils.PrintWarning("Some domains resolve to CloudFront, but to other addresses than the stage's distribution. CloudFront returns different addresses to each resolver, make sure their records point to the CDN domain.")
+
+		return nil
+	}
+
+	utils.PrintSuccess("All domains resolve to the stage")
+
+	return nil
+}
+
+// CloudFront addresses rotate between queries, so a CloudFront host without a shared address is inconclusive.
+func resolvesTo(host string, cdnDomain string) string {
+	cname, err := net.LookupCNAME(host)
+	if err == nil && strings.EqualFold(strings.TrimSuffix(cname, "."), cdnDomain) {
+		return statusOk
+	}
+
+	var addresses []string
+
+	for attempt := 1; attempt <= 3; attempt++ {
+		if attempt > 1 {
+			time.Sleep(2 * time.Second)
+		}
+
+		addresses, err = net.LookupHost(host)
+		if err != nil {
+			return statusNotPointing
+		}
+
+		cdnAddresses, err := net.LookupHost(cdnDomain)
+		if err != nil {
+			continue
+		}
+
+		for _, address := range addresses {
+			if slices.Contains(cdnAddresses, address) {
+				return statusOk
+			}
+		}
+	}
+
+	for _, address := range addresses {
+		names, err := net.LookupAddr(address)
+		if err != nil {
+			continue
+		}
+
+		for _, name := range names {
+			if strings.HasSuffix(strings.TrimSuffix(name, "."), ".cloudfront.net") {
+				return statusInconclusive
+			}
+		}
+	}
+
+	return statusNotPointing
+}
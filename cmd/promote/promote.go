@@ -0,0 +1,54 @@
+package promote
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"hover/aws"
+	"hover/canary"
+	"hover/provisioner"
+	"hover/utils"
+	"hover/utils/manifest"
+)
+
+type options struct {
+	alias string
+}
+
+func Cmd() *cobra.Command {
+	opts := options{}
+
+	cmd := &cobra.Command{
+		Use:   "promote <ALIAS>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Send all traffic to the new version of a canary deployment",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.alias = args[0]
+
+			return Run(&opts)
+		},
+	}
+
+	return cmd
+}
+
+func Run(o *options) error {
+	fmt.Println()
+
+	stage, err := manifest.Get(o.alias)
+	if err != nil {
+		return err
+	}
+
+	utils.PrintStep("Promoting the canary of stage " + stage.Name)
+
+	awsClient, _ := aws.New(stage.AwsProfile, stage.Region)
+
+	err = canary.Promote(provisioner.GetHttpLambdaFunctionNames(stage), awsClient)
+	if err != nil {
+		return err
+	}
+
+	utils.PrintSuccess("The new version is serving all traffic")
+
+	return nil
+}
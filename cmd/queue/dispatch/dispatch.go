@@ -0,0 +1,77 @@
+package dispatch
+
+import (
+	"fmt"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"hover/aws"
+	"hover/provisioner"
+	"hover/utils"
+	"hover/utils/manifest"
+	"strings"
+)
+
+type options struct {
+	stage   string
+	queue   string
+	payload string
+	group   string
+}
+
+func Cmd() *cobra.Command {
+	opts := options{}
+
+	cmd := &cobra.Command{
+		Use:   "dispatch <QUEUE> <PAYLOAD> --stage",
+		Args:  cobra.ExactArgs(2),
+		Short: "Send a raw message to a queue",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.queue = args[0]
+			opts.payload = args[1]
+
+			if opts.stage == "" {
+				return fmt.Errorf("you must specify a --stage")
+			}
+
+			return Run(&opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.stage, "stage", "s", "", "The stage name")
+	cmd.Flags().StringVarP(&opts.group, "group", "g", "default", "The message group of messages sent to FIFO queues")
+
+	return cmd
+}
+
+func Run(o *options) error {
+	fmt.Println()
+
+	stage, err := manifest.Get(o.stage)
+	if err != nil {
+		return err
+	}
+
+	awsClient, _ := aws.New(stage.AwsProfile, stage.Region)
+
+	url, err := provisioner.GetQueueUrl(stage, o.queue, false, awsClient)
+	if err != nil {
+		return err
+	}
+
+	var messageGroupId, deduplicationId *string
+
+	if strings.HasSuffix(*url, ".fifo") {
+		messageGroupId = &o.group
+		deduplicationId = ptr.String(uuid.NewString())
+	}
+
+	err = awsClient.SendQueueMessage(url, &o.payload, nil, messageGroupId, deduplicationId)
+	if err != nil {
+		return fmt.Errorf("unable to send the message. Error: %w", err)
+	}
+
+	utils.PrintSuccess("Message sent to the " + o.queue + " queue")
+
+	return nil
+}
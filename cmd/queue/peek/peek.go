@@ -0,0 +1,114 @@
+package peek
+
+import (
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
+	"hover/aws"
+	"hover/provisioner"
+	"hover/utils"
+	"hover/utils/manifest"
+)
+
+type options struct {
+	stage string
+	queue string
+	count int
+	live  bool
+}
+
+func Cmd() *cobra.Command {
+	opts := options{}
+
+	cmd := &cobra.Command{
+		Use:   "peek <QUEUE> --stage",
+		Args:  cobra.ExactArgs(1),
+		Short: "Show the messages of the dead-letter queue of a queue",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.queue = args[0]
+
+			if opts.stage == "" {
+				return fmt.Errorf("you must specify a --stage")
+			}
+
+			return Run(&opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.stage, "stage", "s", "", "The stage name")
+	cmd.Flags().IntVarP(&opts.count, "count", "c", 10, "The maximum number of messages to show")
+	cmd.Flags().BoolVar(&opts.live, "live", false, "Show the messages of the queue itself, which counts as a receive")
+
+	return cmd
+}
+
+func Run(o *options) error {
+	fmt.Println()
+
+	stage, err := manifest.Get(o.stage)
+	if err != nil {
+		return err
+	}
+
+	awsClient, _ := aws.New(stage.AwsProfile, stage.Region)
+
+	if !o.live && slices.Contains(provisioner.GetQueueNames(stage), o.queue) {
+		_, ok := provisioner.GetDeadLetterQueueName(stage, o.queue)
+		if !ok {
+			return fmt.Errorf("the `%s` queue has no dead-letter queue. Use --live to peek into the queue itself", o.queue)
+		}
+	}
+
+	url, err := provisioner.GetQueueUrl(stage, o.queue, !o.live, awsClient)
+	if err != nil {
+		return err
+	}
+
+	if o.live {
+		utils.PrintWarning("Peeking hides the messages from the workers while it runs and counts as a receive, which may fail jobs or move them to the dead-letter queue.")
+	}
+
+	var messages []types.Message
+
+	for len(messages) < o.count {
+		count := o.count - len(messages)
+		if count > 10 {
+			count = 10
+		}
+
+		received, err := awsClient.ReceiveQueueMessages(url, int32(count), 30)
+		if err != nil {
+			return fmt.Errorf("unable to receive messages. Error: %w", err)
+		}
+
+		if len(received) == 0 {
+			break
+		}
+
+		messages = append(messages, received...)
+	}
+
+	for _, message := range messages {
+		err := awsClient.ReleaseQueueMessage(url, message.ReceiptHandle)
+		if err != nil {
+			return fmt.Errorf("unable to release message %s. Error: %w", *message.MessageId, err)
+		}
+	}
+
+	if len(messages) == 0 {
+		utils.PrintInfo("The queue has no visible messages")
+
+		return nil
+	}
+
+	for _, message := range messages {
+		pterm.FgYellow.Println(*message.MessageId)
+		fmt.Printf("Receive count: %s\n", message.Attributes["ApproximateReceiveCount"])
+		fmt.Println(*message.Body)
+		fmt.Println()
+	}
+
+	return nil
+}
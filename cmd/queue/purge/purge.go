@@ -0,0 +1,73 @@
+package purge
+
+import (
+	"fmt"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"hover/aws"
+	"hover/provisioner"
+	"hover/utils"
+	"hover/utils/manifest"
+	"os"
+)
+
+type options struct {
+	stage      string
+	queue      string
+	deadLetter bool
+}
+
+func Cmd() *cobra.Command {
+	opts := options{}
+
+	cmd := &cobra.Command{
+		Use:   "purge <QUEUE> --stage",
+		Args:  cobra.ExactArgs(1),
+		Short: "Delete all the messages of a queue",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.queue = args[0]
+
+			if opts.stage == "" {
+				return fmt.Errorf("you must specify a --stage")
+			}
+
+			return Run(&opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.stage, "stage", "s", "", "The stage name")
+	cmd.Flags().BoolVar(&opts.deadLetter, "dead-letter", false, "Purge the dead-letter queue instead")
+
+	return cmd
+}
+
+func Run(o *options) error {
+	fmt.Println()
+
+	stage, err := manifest.Get(o.stage)
+	if err != nil {
+		return err
+	}
+
+	awsClient, _ := aws.New(stage.AwsProfile, stage.Region)
+
+	url, err := provisioner.GetQueueUrl(stage, o.queue, o.deadLetter, awsClient)
+	if err != nil {
+		return err
+	}
+
+	result, _ := pterm.DefaultInteractiveConfirm.Show(fmt.Sprintf("Are you sure you want to delete all the messages of `%s`?", *url))
+	if !result {
+		fmt.Println("abort")
+		os.Exit(0)
+	}
+
+	err = awsClient.PurgeQueue(url)
+	if err != nil {
+		return fmt.Errorf("unable to purge the queue. Error: %w", err)
+	}
+
+	utils.PrintSuccess("Queue purged. It may take up to a minute for all the messages to be deleted.")
+
+	return nil
+}
@@ -0,0 +1,25 @@
+package queue
+
+import (
+	"github.com/spf13/cobra"
+	dispatchCmd "hover/cmd/queue/dispatch"
+	peekCmd "hover/cmd/queue/peek"
+	purgeCmd "hover/cmd/queue/purge"
+	redriveCmd "hover/cmd/queue/redrive"
+	statsCmd "hover/cmd/queue/stats"
+)
+
+func Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "queue <command>",
+		Short: "Inspect and manage the queues of a stage",
+	}
+
+	cmd.AddCommand(statsCmd.Cmd())
+	cmd.AddCommand(peekCmd.Cmd())
+	cmd.AddCommand(redriveCmd.Cmd())
+	cmd.AddCommand(purgeCmd.Cmd())
+	cmd.AddCommand(dispatchCmd.Cmd())
+
+	return cmd
+}
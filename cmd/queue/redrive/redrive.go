@@ -0,0 +1,130 @@
+package redrive
+
+import (
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/google/uuid"
+	"github.com/spf13/cobra"
+	"hover/aws"
+	"hover/provisioner"
+	"hover/utils"
+	"hover/utils/manifest"
+	"strings"
+)
+
+type options struct {
+	stage string
+	queue string
+}
+
+func Cmd() *cobra.Command {
+	opts := options{}
+
+	cmd := &cobra.Command{
+		Use:   "redrive <QUEUE> --stage",
+		Args:  cobra.ExactArgs(1),
+		Short: "Move the messages of the dead-letter queue back to the queue",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.queue = args[0]
+
+			if opts.stage == "" {
+				return fmt.Errorf("you must specify a --stage")
+			}
+
+			return Run(&opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.stage, "stage", "s", "", "The stage name")
+
+	return cmd
+}
+
+func Run(o *options) error {
+	fmt.Println()
+
+	stage, err := manifest.Get(o.stage)
+	if err != nil {
+		return err
+	}
+
+	awsClient, _ := aws.New(stage.AwsProfile, stage.Region)
+
+	url, err := provisioner.GetQueueUrl(stage, o.queue, false, awsClient)
+	if err != nil {
+		return err
+	}
+
+	deadLetterUrl, err := provisioner.GetQueueUrl(stage, o.queue, true, awsClient)
+	if err != nil {
+		return err
+	}
+
+	utils.PrintStep("Moving messages to the " + o.queue + " queue")
+
+	moved := 0
+	seen := map[string]bool{}
+
+	// Shared dead-letter queues also hold the messages of other queues.
+	var skipped []types.Message
+
+	for {
+		messages, err := awsClient.ReceiveQueueMessages(deadLetterUrl, 10, 60)
+		if err != nil {
+			return fmt.Errorf("unable to receive messages. Error: %w", err)
+		}
+
+		received := 0
+
+		for _, message := range messages {
+			if seen[*message.MessageId] {
+				continue
+			}
+
+			seen[*message.MessageId] = true
+			received++
+
+			if !strings.HasSuffix(message.Attributes["DeadLetterQueueSourceArn"], ":"+provisioner.GetQueueName(stage, o.queue)) {
+				skipped = append(skipped, message)
+
+				continue
+			}
+
+			var messageGroupId, deduplicationId *string
+
+			// A new deduplication ID keeps FIFO queues from dropping the messages as duplicates.
+			if strings.HasSuffix(*url, ".fifo") {
+				messageGroupId = ptr.String(message.Attributes["MessageGroupId"])
+				deduplicationId = ptr.String(uuid.NewString())
+			}
+
+			err := awsClient.SendQueueMessage(url, message.Body, message.MessageAttributes, messageGroupId, deduplicationId)
+			if err != nil {
+				return fmt.Errorf("unable to send message %s. Error: %w", *message.MessageId, err)
+			}
+
+			err = awsClient.DeleteQueueMessage(deadLetterUrl, message.ReceiptHandle)
+			if err != nil {
+				return fmt.Errorf("unable to delete message %s from the dead-letter queue. Error: %w", *message.MessageId, err)
+			}
+
+			moved++
+		}
+
+		if received == 0 {
+			break
+		}
+	}
+
+	for _, message := range skipped {
+		err := awsClient.ReleaseQueueMessage(deadLetterUrl, message.ReceiptHandle)
+		if err != nil {
+			return fmt.Errorf("unable to release message %s. Error: %w", *message.MessageId, err)
+		}
+	}
+
+	utils.PrintSuccess(fmt.Sprintf("Moved %d messages to the %s queue", moved, o.queue))
+
+	return nil
+}
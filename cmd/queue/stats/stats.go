@@ -0,0 +1,107 @@
+package stats
+
+import (
+	"fmt"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"hover/aws"
+	"hover/provisioner"
+	"hover/utils/manifest"
+	"time"
+)
+
+type options struct {
+	stage string
+}
+
+func Cmd() *cobra.Command {
+	opts := options{}
+
+	cmd := &cobra.Command{
+		Use:   "stats --stage",
+		Args:  cobra.NoArgs,
+		Short: "Show the message counts of the queues of the specified stage",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if opts.stage == "" {
+				return fmt.Errorf("you must specify a --stage")
+			}
+
+			return Run(&opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.stage, "stage", "s", "", "The stage name")
+
+	return cmd
+}
+
+func Run(o *options) error {
+	fmt.Println()
+
+	stage, err := manifest.Get(o.stage)
+	if err != nil {
+		return err
+	}
+
+	queueNames := provisioner.GetQueueNames(stage)
+	if len(queueNames) == 0 {
+		return fmt.Errorf("the stage `%s` has no queues", o.stage)
+	}
+
+	awsClient, _ := aws.New(stage.AwsProfile, stage.Region)
+
+	tableData := pterm.TableData{
+		{"Queue", "Visible", "In Flight", "Delayed", "Dead-Letter", "Oldest Message"},
+	}
+
+	for _, queueName := range queueNames {
+		attributes, err := getQueueAttributes(stage, queueName, false, awsClient)
+		if err != nil {
+			return err
+		}
+
+		deadLetterCount := "-"
+
+		if _, ok := provisioner.GetDeadLetterQueueName(stage, queueName); ok {
+			deadLetterAttributes, err := getQueueAttributes(stage, queueName, true, awsClient)
+			if err != nil {
+				return err
+			}
+
+			deadLetterCount = deadLetterAttributes["ApproximateNumberOfMessages"]
+		}
+
+		age, err := awsClient.GetQueueMetricLatest(ptr.String(provisioner.GetQueueName(stage, queueName)), "ApproximateAgeOfOldestMessage")
+		if err != nil {
+			return fmt.Errorf("unable to read the metrics of the `%s` queue. Error: %w", queueName, err)
+		}
+
+		tableData = append(tableData, []string{
+			queueName,
+			attributes["ApproximateNumberOfMessages"],
+			attributes["ApproximateNumberOfMessagesNotVisible"],
+			attributes["ApproximateNumberOfMessagesDelayed"],
+			deadLetterCount,
+			(time.Duration(age) * time.Second).String(),
+		})
+	}
+
+	pterm.DefaultTable.WithHasHeader().WithData(tableData).Render()
+
+	return nil
+}
+
+func getQueueAttributes(stage *manifest.Manifest, queueName string, deadLetter bool, awsClient *aws.Aws) (map[string]string, error) {
+	url, err := provisioner.GetQueueUrl(stage, queueName, deadLetter, awsClient)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes, err := awsClient.GetQueueAttributes(url)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the attributes of the `%s` queue. Error: %w", queueName, err)
+	}
+
+	return attributes, nil
+}
@@ -4,11 +4,18 @@ import (
 	"fmt"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
+	abortCmd "hover/cmd/abort"
 	buildCmd "hover/cmd/build"
 	commandCmd "hover/cmd/command"
 	deployCmd "hover/cmd/deploy"
+	domainCmd "hover/cmd/domain"
+	downCmd "hover/cmd/down"
+	promoteCmd "hover/cmd/promote"
+	queueCmd "hover/cmd/queue"
 	secretCmd "hover/cmd/secret"
 	stageCmd "hover/cmd/stage"
+	storageCmd "hover/cmd/storage"
+	upCmd "hover/cmd/up"
 	"os"
 )
 
@@ -40,6 +47,13 @@ func init() {
 	rootCmd.AddCommand(secretCmd.Cmd())
 	rootCmd.AddCommand(deployCmd.Cmd())
 	rootCmd.AddCommand(buildCmd.Cmd())
+	rootCmd.AddCommand(domainCmd.Cmd())
+	rootCmd.AddCommand(downCmd.Cmd())
+	rootCmd.AddCommand(upCmd.Cmd())
+	rootCmd.AddCommand(promoteCmd.Cmd())
+	rootCmd.AddCommand(abortCmd.Cmd())
+	rootCmd.AddCommand(queueCmd.Cmd())
+	rootCmd.AddCommand(storageCmd.Cmd())
 
 	rootCmd.SetVersionTemplate(pterm.FgMagenta.Sprint("HOVER") + " version " + pterm.FgYellow.Sprint("{{.Version}}") + "\n")
 
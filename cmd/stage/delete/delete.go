@@ -2,12 +2,14 @@ package delete
 
 import (
 	"fmt"
+	cloudformationTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/aws/smithy-go/ptr"
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
 	"hover/aws"
+	"hover/provisioner"
 	"hover/utils"
 	"hover/utils/manifest"
 	"os"
@@ -52,9 +54,14 @@ func Run(o *options) error {
 
 	awsClient, _ := aws.New(stage.AwsProfile, stage.Region)
 
-	_, err = awsClient.GetStack(&stage.Name)
+	stack, err := awsClient.GetStack(&stage.Name)
 	if err == nil {
-		err := deleteStack(stage, awsClient)
+		err := deleteS3Triggers(stage, &stack, awsClient)
+		if err != nil {
+			return err
+		}
+
+		err = deleteStack(stage, awsClient)
 		if err != nil {
 			return err
 		}
@@ -66,6 +73,10 @@ func Run(o *options) error {
 		}
 	}
 
+	if err = deleteFirewall(stage, awsClient); err != nil {
+		return err
+	}
+
 	if err = deleteEcrRepo(stage, awsClient); err != nil {
 		return err
 	}
@@ -83,6 +94,32 @@ func Run(o *options) error {
 	return nil
 }
 
+func deleteS3Triggers(stage *manifest.Manifest, stack *cloudformationTypes.Stack, aws *aws.Aws) error {
+	if len(provisioner.GetS3TriggerBuckets(stage, stack)) == 0 {
+		return nil
+	}
+
+	utils.PrintStep("Removing S3 triggers")
+
+	return provisioner.DeleteS3Triggers(stage, stack, aws)
+}
+
+func deleteFirewall(stage *manifest.Manifest, aws *aws.Aws) error {
+	if !stage.Firewall.Enabled() {
+		return nil
+	}
+
+	utils.PrintStep("Deleting the firewall")
+
+	// The web ACL can't be deleted while the CloudFront distribution still uses it.
+	err := provisioner.WaitForStackDeletion(stage.Name, aws)
+	if err != nil {
+		return err
+	}
+
+	return provisioner.DeleteFirewall(stage, aws)
+}
+
 func deleteEcrRepo(stage *manifest.Manifest, aws *aws.Aws) error {
 	utils.PrintStep("Deleting ECR repository")
 
@@ -0,0 +1,17 @@
+package storage
+
+import (
+	"github.com/spf13/cobra"
+	syncCmd "hover/cmd/storage/sync"
+)
+
+func Cmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "storage <command>",
+		Short: "Manage the storage bucket of a stage",
+	}
+
+	cmd.AddCommand(syncCmd.Cmd())
+
+	return cmd
+}
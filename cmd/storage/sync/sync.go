@@ -0,0 +1,161 @@
+package sync
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/smithy-go/ptr"
+	"github.com/spf13/cobra"
+	"hover/aws"
+	"hover/provisioner"
+	"hover/utils"
+	"hover/utils/manifest"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type options struct {
+	stage     string
+	directory string
+	prefix    string
+}
+
+func Cmd() *cobra.Command {
+	opts := options{}
+
+	cmd := &cobra.Command{
+		Use:   "sync <DIRECTORY> --stage",
+		Args:  cobra.ExactArgs(1),
+		Short: "Upload the files of a local directory to the storage bucket of the specified stage",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.directory = args[0]
+
+			if opts.stage == "" {
+				return fmt.Errorf("you must specify a --stage")
+			}
+
+			return Run(&opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.stage, "stage", "s", "", "The stage name")
+	cmd.Flags().StringVarP(&opts.prefix, "prefix", "p", "", "The prefix of the uploaded object keys")
+
+	return cmd
+}
+
+func Run(o *options) error {
+	fmt.Println()
+
+	stage, err := manifest.Get(o.stage)
+	if err != nil {
+		return err
+	}
+
+	if stage.Storage == nil {
+		return fmt.Errorf("the stage `%s` has no storage bucket. Add a `storage` section to its manifest and deploy it first", o.stage)
+	}
+
+	info, err := os.Stat(o.directory)
+	if err != nil || !info.IsDir() {
+		return fmt.Errorf("the directory `%s` doesn't exist", o.directory)
+	}
+
+	awsClient, _ := aws.New(stage.AwsProfile, stage.Region)
+
+	stack, err := awsClient.GetStack(&stage.Name)
+	if err != nil {
+		return fmt.Errorf("unable to read the CloudFormation stack of the stage. Error: %w", err)
+	}
+
+	bucketName := provisioner.GetStackOutput(&stack, "StorageBucket")
+	if bucketName == "" {
+		return fmt.Errorf("the storage bucket of the stage `%s` doesn't exist yet. Deploy the stage first", o.stage)
+	}
+
+	utils.PrintStep("Syncing " + o.directory + " to the " + bucketName + " bucket")
+
+	// Single-part uploads have the MD5 hash of their content as ETag.
+	existingObjects := map[string]string{}
+
+	err = awsClient.WalkBucketObjects(&bucketName, func(output *s3.ListObjectsV2Output) error {
+		for _, object := range output.Contents {
+			existingObjects[*object.Key] = strings.Trim(*object.ETag, `"`)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("unable to list the objects of the %s bucket. Error: %w", bucketName, err)
+	}
+
+	uploaded := 0
+	skipped := 0
+
+	err = filepath.WalkDir(o.directory, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			return nil
+		}
+
+		relativePath, _ := filepath.Rel(o.directory, path)
+		objectKey := o.prefix + filepath.ToSlash(relativePath)
+
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+
+		defer file.Close()
+
+		hash := md5.New()
+
+		_, err = io.Copy(hash, file)
+		if err != nil {
+			return err
+		}
+
+		if existingObjects[objectKey] == hex.EncodeToString(hash.Sum(nil)) {
+			skipped++
+
+			return nil
+		}
+
+		_, err = file.Seek(0, io.SeekStart)
+		if err != nil {
+			return err
+		}
+
+		var contentType *string
+
+		if mimeType := mime.TypeByExtension(filepath.Ext(path)); mimeType != "" {
+			contentType = ptr.String(mimeType)
+		}
+
+		err = awsClient.UploadFileToBucket(&bucketName, &objectKey, contentType, file)
+		if err != nil {
+			return fmt.Errorf("unable to upload %s. Error: %w", path, err)
+		}
+
+		fmt.Println("Uploaded " + objectKey)
+
+		uploaded++
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	utils.PrintSuccess(fmt.Sprintf("Uploaded %d files, %d files were unchanged", uploaded, skipped))
+
+	return nil
+}
@@ -0,0 +1,55 @@
+package up
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"hover/aws"
+	"hover/provisioner"
+	"hover/utils"
+	"hover/utils/manifest"
+)
+
+type options struct {
+	alias string
+}
+
+func Cmd() *cobra.Command {
+	opts := options{}
+
+	cmd := &cobra.Command{
+		Use:   "up <ALIAS>",
+		Args:  cobra.ExactArgs(1),
+		Short: "Bring the stage out of maintenance mode",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.alias = args[0]
+
+			return Run(&opts)
+		},
+	}
+
+	return cmd
+}
+
+func Run(o *options) error {
+	fmt.Println()
+
+	stage, err := manifest.Get(o.alias)
+	if err != nil {
+		return err
+	}
+
+	utils.PrintStep("Turning maintenance mode off for stage " + stage.Name)
+
+	awsClient, _ := aws.New(stage.AwsProfile, stage.Region)
+
+	err = provisioner.SetMaintenanceMode(stage, false, awsClient)
+	if err != nil {
+		return err
+	}
+
+	utils.PrintSuccess("Stage is live")
+
+	utils.PrintInfo("It may take a few minutes for the change to propagate to all CloudFront edge locations.")
+
+	return nil
+}
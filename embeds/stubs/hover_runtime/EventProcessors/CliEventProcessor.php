@@ -5,19 +5,49 @@ use Symfony\Component\Process\Process;
 
 class CliEventProcessor extends AbstractEventProcessor
 {
+    public array $manifest;
+
+    public function __construct(array $manifest)
+    {
+        $this->manifest = $manifest;
+    }
+
     public function process(array $invocationBody, string $invocationId, int $invocationDeadline): array
     {
+        // S3 and SNS deliver their own events, which are passed as the payload
+        // of the command of the matching trigger.
+        if (isset($invocationBody['Records'])) {
+            $invocationBody = [
+                'command' => $this->getTriggerCommand($invocationBody['Records'][0] ?? []),
+                'payload' => $invocationBody,
+            ];
+        }
+
         $timeout = $invocationDeadline - intval(microtime(true) * 1000);
 
         fwrite(STDERR,
             sprintf('Hover: Executing php artisan %s', trim($invocationBody['command'])).PHP_EOL
         );
 
+        $environment = [];
+
+        // Scheduled and triggered commands may receive an input payload, which is
+        // written to a file the command can read using the HOVER_PAYLOAD_PATH variable.
+        if (isset($invocationBody['payload'])) {
+            $payloadPath = sprintf('/tmp/hover-payload-%s.json', $invocationId);
+
+            file_put_contents($payloadPath, json_encode($invocationBody['payload']));
+
+            $environment['HOVER_PAYLOAD_PATH'] = $payloadPath;
+        }
+
         $process = Process::fromShellCommandline(
             sprintf('php %s/artisan %s --no-interaction 2>&1',
                 $_ENV['LAMBDA_TASK_ROOT'],
                 trim($invocationBody['command'])
-            )
+            ),
+            null,
+            $environment
         )->setTimeout(ceil($timeout / 1000) - 1);
 
         try {
@@ -28,6 +58,10 @@ class CliEventProcessor extends AbstractEventProcessor
             });
         } catch (ProcessTimedOutException $e) {
             throw new Exception('CLI command timed out 1 second before Lambda times out.');
+        } finally {
+            if (isset($payloadPath)) {
+                @unlink($payloadPath);
+            }
         }
 
         return [
@@ -35,4 +69,31 @@ class CliEventProcessor extends AbstractEventProcessor
             'output' => base64_encode($output),
         ];
     }
+
+    protected function getTriggerCommand(array $record): string
+    {
+        foreach ($this->manifest['triggers'] ?? [] as $trigger) {
+            if ($trigger['type'] === 's3' && ($record['eventSource'] ?? null) === 'aws:s3') {
+                $key = urldecode($record['s3']['object']['key']);
+                $events = $trigger['events'] ?: ['s3:ObjectCreated:*'];
+
+                $matches = $record['s3']['bucket']['name'] === $trigger['bucket']
+                    && str_starts_with($key, $trigger['prefix'])
+                    && str_ends_with($key, $trigger['suffix'])
+                    && count(array_filter($events, fn ($event) => fnmatch($event, 's3:'.$record['eventName']))) > 0;
+
+                if ($matches) {
+                    return $trigger['command'];
+                }
+            }
+
+            if ($trigger['type'] === 'sns' && ($record['EventSource'] ?? null) === 'aws:sns') {
+                if ($record['Sns']['TopicArn'] === $trigger['topic']) {
+                    return $trigger['command'];
+                }
+            }
+        }
+
+        throw new Exception('No trigger matches the received event!');
+    }
 }
\ No newline at end of file
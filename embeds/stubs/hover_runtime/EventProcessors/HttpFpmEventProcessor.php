@@ -36,11 +36,32 @@ class HttpFpmEventProcessor extends AbstractEventProcessor
             throw new Exception('Unexpected invocation type!');
         }
 
-        $response = $this->sendRequestToFpm(
-            $invocationBody, $invocationId, $invocationDeadline
+        $response = $this->apiGateway->transformResponse(
+            $this->sendRequestToFpm($invocationBody, $invocationId, $invocationDeadline)
         );
 
-        return $this->apiGateway->transformResponse($response);
+        if ($response['statusCode'] >= 500) {
+            $this->recordServerError();
+        }
+
+        return $response;
+    }
+
+    private function recordServerError()
+    {
+        fwrite(STDOUT, json_encode([
+            '_aws' => [
+                'Timestamp' => intval(microtime(true) * 1000),
+                'CloudWatchMetrics' => [[
+                    'Namespace' => 'Hover',
+                    'Dimensions' => [['FunctionName', 'ExecutedVersion']],
+                    'Metrics' => [['Name' => 'ServerErrors', 'Unit' => 'Count']],
+                ]],
+            ],
+            'FunctionName' => $_ENV['AWS_LAMBDA_FUNCTION_NAME'],
+            'ExecutedVersion' => $_ENV['AWS_LAMBDA_FUNCTION_VERSION'],
+            'ServerErrors' => 1,
+        ]).PHP_EOL);
     }
 
     public function sendRequestToFpm($invocationBody, $invocationId, $invocationDeadline)
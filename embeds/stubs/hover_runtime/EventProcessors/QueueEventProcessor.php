@@ -46,18 +46,45 @@ class QueueEventProcessor extends AbstractEventProcessor
     }
 
     public function process(array $invocationBody, string $invocationId, int $invocationDeadline): array
+    {
+        $failures = [];
+
+        foreach ($invocationBody['Records'] as $record) {
+            // Messages of a FIFO queue that follow a failed message are
+            // returned too, so they're retried in their original order.
+            if (count($failures) > 0 && ($this->config['fifo'] ?? false)) {
+                $failures[] = ['itemIdentifier' => $record['messageId']];
+
+                continue;
+            }
+
+            if (! $this->processRecord($record, $invocationDeadline)) {
+                $failures[] = ['itemIdentifier' => $record['messageId']];
+            }
+        }
+
+        if (count($failures) > 0) {
+            return [
+                "batchItemFailures" => $failures
+            ];
+        }
+
+        return [];
+    }
+
+    protected function processRecord(array $record, int $invocationDeadline): bool
     {
         $timeout = $invocationDeadline - intval(microtime(true) * 1000);
 
         $jobData = [
-            'MessageId' => $invocationBody['Records'][0]['messageId'],
-            'ReceiptHandle' => $invocationBody['Records'][0]['receiptHandle'],
-            'Body' => $invocationBody['Records'][0]['body'],
-            'Attributes' => $invocationBody['Records'][0]['attributes'],
-            'MessageAttributes' => $invocationBody['Records'][0]['messageAttributes'],
+            'MessageId' => $record['messageId'],
+            'ReceiptHandle' => $record['receiptHandle'],
+            'Body' => $record['body'],
+            'Attributes' => $record['attributes'],
+            'MessageAttributes' => $record['messageAttributes'],
         ];
 
-        $queueUrl = $this->getQueueUrl($invocationBody['Records'][0]);
+        $queueUrl = $this->getQueueUrl($record);
 
         self::$currentJob = new SqsJob(
             $this->application,
@@ -77,15 +104,7 @@ class QueueEventProcessor extends AbstractEventProcessor
 
         $this->worker->daemon('sqs', $queueUrl, $workerOptions);
 
-        if (self::$currentJob->isReleased()) {
-            return [
-                "batchItemFailures" => [
-                    ['itemIdentifier' => $invocationBody['Records'][0]['messageId']]
-                ]
-            ];
-        }
-
-        return [];
+        return ! self::$currentJob->isReleased();
     }
 
     protected function extractWorkerDependencies(): array
@@ -0,0 +1,61 @@
+<?php
+
+use Aws\DynamoDb\DynamoDbClient;
+use Illuminate\Container\Container;
+
+class WebsocketEventProcessor extends AbstractEventProcessor
+{
+    public Container $application;
+    public DynamoDbClient $dynamoDb;
+
+    public function __construct(Container $application)
+    {
+        $this->application = $application;
+
+        $this->dynamoDb = new DynamoDbClient([
+            'region' => $_ENV['AWS_DEFAULT_REGION'],
+            'version' => 'latest',
+        ]);
+    }
+
+    public function process(array $invocationBody, string $invocationId, int $invocationDeadline): array
+    {
+        if (! isset($invocationBody['requestContext']['connectionId'])) {
+            throw new Exception('Unexpected invocation type!');
+        }
+
+        $connectionId = $invocationBody['requestContext']['connectionId'];
+        $routeKey = $invocationBody['requestContext']['routeKey'];
+
+        if ($routeKey === '$connect') {
+            $this->dynamoDb->putItem([
+                'TableName' => $_ENV['WEBSOCKET_CONNECTIONS_TABLE'],
+                'Item' => [
+                    'connectionId' => ['S' => $connectionId],
+                    // API Gateway closes connections after 2 hours.
+                    'expires_at' => ['N' => (string) (time() + 7200)],
+                ],
+            ]);
+        }
+
+        if ($routeKey === '$disconnect') {
+            $this->dynamoDb->deleteItem([
+                'TableName' => $_ENV['WEBSOCKET_CONNECTIONS_TABLE'],
+                'Key' => [
+                    'connectionId' => ['S' => $connectionId],
+                ],
+            ]);
+        }
+
+        $payload = json_decode($invocationBody['body'] ?? '', true);
+
+        // Listeners of these events receive the connection ID, the decoded
+        // message payload and the raw API Gateway event.
+        $this->application['events']->dispatch(
+            'hover.websocket.'.trim($routeKey, '$'),
+            [$connectionId, $payload, $invocationBody]
+        );
+
+        return ['statusCode' => 200];
+    }
+}
\ No newline at end of file
@@ -1,6 +1,7 @@
 <?php
 
 use Aws\Kms\KmsClient;
+use Aws\SecretsManager\SecretsManagerClient;
 use Dotenv\Dotenv;
 use Illuminate\Contracts\Console\Kernel as ConsoleKernelContract;
 
@@ -89,4 +90,27 @@ class Hover
             $dotenv->load();
         }
     }
+
+    public function populateDatabaseCredentials()
+    {
+        if (! isset($_ENV['DB_PASSWORD_SECRET'])) {
+            return;
+        }
+
+        fwrite(STDERR, "Hover: populating database credentials.".PHP_EOL);
+
+        $client = new SecretsManagerClient([
+            'region' => $_ENV['AWS_DEFAULT_REGION'],
+            'version' => 'latest',
+        ]);
+
+        $secret = json_decode($client->getSecretValue([
+            'SecretId' => $_ENV['DB_PASSWORD_SECRET'],
+        ])['SecretString'], true);
+
+        foreach (['DB_USERNAME' => $secret['username'], 'DB_PASSWORD' => $secret['password']] as $key => $value) {
+            $_ENV[$key] = $value;
+            $_SERVER[$key] = $value;
+        }
+    }
 }
\ No newline at end of file
@@ -0,0 +1,66 @@
+package provisioner
+
+import (
+	"fmt"
+	"hover/aws"
+	"hover/utils/manifest"
+	"strings"
+)
+
+func getCacheTableName(manifest *manifest.Manifest) string {
+	return manifest.Name + "-cache"
+}
+
+func cacheTable(manifest *manifest.Manifest) map[string]any {
+	return map[string]any{
+		"CacheTable": map[string]any{
+			"Type": "AWS::DynamoDB::Table",
+			"Properties": map[string]any{
+				"TableName":   getCacheTableName(manifest),
+				"BillingMode": "PAY_PER_REQUEST",
+				"AttributeDefinitions": []any{
+					map[string]any{
+						"AttributeName": "key",
+						"AttributeType": "S",
+					},
+				},
+				"KeySchema": []any{
+					map[string]any{
+						"AttributeName": "key",
+						"KeyType":       "HASH",
+					},
+				},
+				"TimeToLiveSpecification": map[string]any{
+					"AttributeName": "expires_at",
+					"Enabled":       true,
+				},
+			},
+		},
+	}
+}
+
+func VerifyCacheTableAccess(manifest *manifest.Manifest, aws *aws.Aws) error {
+	roleArn := strings.Split(manifest.Auth.LambdaRole, ":")
+	if len(roleArn) < 5 {
+		return fmt.Errorf("invalid Lambda execution role ARN `%s`", manifest.Auth.LambdaRole)
+	}
+
+	tableArn := fmt.Sprintf("arn:%s:dynamodb:%s:%s:table/%s", roleArn[1], manifest.Region, roleArn[4], getCacheTableName(manifest))
+
+	deniedActions, err := aws.GetDeniedActions(&manifest.Auth.LambdaRole, []string{
+		"dynamodb:GetItem",
+		"dynamodb:BatchGetItem",
+		"dynamodb:PutItem",
+		"dynamodb:UpdateItem",
+		"dynamodb:DeleteItem",
+	}, &tableArn)
+	if err != nil {
+		return fmt.Errorf("unable to check the permissions of the Lambda execution role. Error: %w", err)
+	}
+
+	if len(deniedActions) > 0 {
+		return fmt.Errorf("the Lambda execution role isn't allowed to use %s on the cache table", strings.Join(deniedActions, ", "))
+	}
+
+	return nil
+}
@@ -0,0 +1,185 @@
+package provisioner
+
+import (
+	"encoding/json"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	cloudformationTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
+	"golang.org/x/exp/slices"
+	"hover/aws"
+	"hover/utils/manifest"
+	"strconv"
+)
+
+type concurrencySchedule struct {
+	functionName            string
+	resourceName            string
+	lambdaResourceName      string
+	lambdaAliasResourceName string
+	provisionedConcurrency  int
+	schedules               []manifest.ConcurrencySchedule
+}
+
+func getConcurrencySchedules(manifest *manifest.Manifest) []concurrencySchedule {
+	var result []concurrencySchedule
+
+	if len(manifest.HTTP.ProvisionedConcurrencySchedule) > 0 {
+		result = append(result, concurrencySchedule{
+			functionName:            GetLambdaFunctionName(manifest.Name, "http"),
+			resourceName:            "HTTPLambdaScalableTarget",
+			lambdaResourceName:      "HTTPLambda",
+			lambdaAliasResourceName: "HTTPLambdaLiveAlias",
+			provisionedConcurrency:  manifest.HTTP.ProvisionedConcurrency,
+			schedules:               manifest.HTTP.ProvisionedConcurrencySchedule,
+		})
+	}
+
+	for queueFunctionName, queueConfiguration := range manifest.Queue {
+		if len(queueConfiguration.ProvisionedConcurrencySchedule) > 0 {
+			result = append(result, concurrencySchedule{
+				functionName:            GetLambdaFunctionName(manifest.Name, queueFunctionName+"-queue"),
+				resourceName:            queueFunctionName + "QueueLambdaScalableTarget",
+				lambdaResourceName:      queueFunctionName + "QueueLambda",
+				lambdaAliasResourceName: queueFunctionName + "LambdaLiveAlias",
+				provisionedConcurrency:  queueConfiguration.ProvisionedConcurrency,
+				schedules:               queueConfiguration.ProvisionedConcurrencySchedule,
+			})
+		}
+	}
+
+	return result
+}
+
+func getStaticProvisionedConcurrency(manifest *manifest.Manifest) map[string]int {
+	result := map[string]int{}
+
+	if manifest.HTTP.ProvisionedConcurrency > 0 && len(manifest.HTTP.ProvisionedConcurrencySchedule) == 0 {
+		result[GetLambdaFunctionName(manifest.Name, "http")] = manifest.HTTP.ProvisionedConcurrency
+	}
+
+	for queueFunctionName, queueConfiguration := range manifest.Queue {
+		if queueConfiguration.ProvisionedConcurrency > 0 && len(queueConfiguration.ProvisionedConcurrencySchedule) == 0 {
+			result[GetLambdaFunctionName(manifest.Name, queueFunctionName+"-queue")] = queueConfiguration.ProvisionedConcurrency
+		}
+	}
+
+	return result
+}
+
+// Provisioned concurrency can't be set on an alias pointing to $LATEST, so it's set once the new versions are activated.
+func GetProvisionedConcurrencyChanges(manifest *manifest.Manifest, previousStack *cloudformationTypes.Stack) map[string]int {
+	previous := map[string]int{}
+
+	if value := GetStackOutput(previousStack, "ProvisionedConcurrency"); value != "" {
+		_ = json.Unmarshal([]byte(value), &previous)
+	}
+
+	current := getStaticProvisionedConcurrency(manifest)
+
+	var scheduledFunctions []string
+
+	for _, schedule := range getConcurrencySchedules(manifest) {
+		scheduledFunctions = append(scheduledFunctions, schedule.functionName)
+	}
+
+	result := map[string]int{}
+
+	for functionName, concurrency := range current {
+		if previous[functionName] != concurrency {
+			result[functionName] = concurrency
+		}
+	}
+
+	for functionName := range previous {
+		_, exists := current[functionName]
+
+		// Functions moved to a schedule are left to Application Auto Scaling.
+		if !exists && !slices.Contains(scheduledFunctions, functionName) {
+			result[functionName] = 0
+		}
+	}
+
+	return result
+}
+
+func getPublishedFunctions(manifest *manifest.Manifest, aws *aws.Aws) []string {
+	var result []string
+
+	for _, schedule := range getConcurrencySchedules(manifest) {
+		alias, err := aws.GetLambdaAlias(ptr.String(schedule.functionName), ptr.String("live"))
+		if err == nil && *alias.FunctionVersion != "$LATEST" {
+			result = append(result, schedule.functionName)
+		}
+	}
+
+	return result
+}
+
+func HasPendingConcurrencySchedules(manifest *manifest.Manifest, resources *cloudformation.DescribeStackResourcesOutput) bool {
+	for _, schedule := range getConcurrencySchedules(manifest) {
+		found := false
+
+		for _, resource := range resources.StackResources {
+			if *resource.LogicalResourceId == schedule.resourceName {
+				found = true
+			}
+		}
+
+		if !found {
+			return true
+		}
+	}
+
+	return false
+}
+
+func provisionedConcurrencySchedule(schedule concurrencySchedule) map[string]any {
+	minCapacity := schedule.provisionedConcurrency
+	maxCapacity := schedule.provisionedConcurrency
+
+	var scheduledActions []any
+
+	for i, entry := range schedule.schedules {
+		if entry.Concurrency < minCapacity {
+			minCapacity = entry.Concurrency
+		}
+
+		if entry.Concurrency > maxCapacity {
+			maxCapacity = entry.Concurrency
+		}
+
+		action := map[string]any{
+			"ScheduledActionName": schedule.resourceName + "-" + strconv.Itoa(i+1),
+			"Schedule":            "cron(" + entry.Cron + ")",
+			"ScalableTargetAction": map[string]any{
+				"MinCapacity": entry.Concurrency,
+				"MaxCapacity": entry.Concurrency,
+			},
+		}
+
+		if entry.Timezone != "" {
+			action["Timezone"] = entry.Timezone
+		}
+
+		scheduledActions = append(scheduledActions, action)
+	}
+
+	return map[string]any{
+		schedule.resourceName: map[string]any{
+			"Type": "AWS::ApplicationAutoScaling::ScalableTarget",
+			"DependsOn": []any{
+				schedule.lambdaAliasResourceName,
+			},
+			"Properties": map[string]any{
+				"ServiceNamespace":  "lambda",
+				"ScalableDimension": "lambda:function:ProvisionedConcurrency",
+				"ResourceId": map[string]any{
+					"Fn::Sub": "function:${" + schedule.lambdaResourceName + "}:live",
+				},
+				"MinCapacity":      minCapacity,
+				"MaxCapacity":      maxCapacity,
+				"ScheduledActions": scheduledActions,
+			},
+		},
+	}
+}
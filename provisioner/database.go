@@ -0,0 +1,155 @@
+package provisioner
+
+import (
+	"encoding/json"
+	"hover/utils/manifest"
+	"strconv"
+)
+
+type databaseEngine struct {
+	engine     string
+	connection string
+	port       int
+}
+
+var databaseEngines = map[string]databaseEngine{
+	"mysql":    {engine: "aurora-mysql", connection: "mysql", port: 3306},
+	"postgres": {engine: "aurora-postgresql", connection: "pgsql", port: 5432},
+}
+
+func getDatabaseName(manifest *manifest.Manifest) string {
+	if manifest.Database.Name == "" {
+		return "laravel"
+	}
+
+	return manifest.Database.Name
+}
+
+func getDatabaseUsername(manifest *manifest.Manifest) string {
+	if manifest.Database.Username == "" {
+		return "hover"
+	}
+
+	return manifest.Database.Username
+}
+
+func database(manifest *manifest.Manifest) map[string]any {
+	engine := databaseEngines[manifest.Database.Engine]
+
+	minCapacity := manifest.Database.MinCapacity
+	if minCapacity == 0 {
+		minCapacity = 0.5
+	}
+
+	maxCapacity := manifest.Database.MaxCapacity
+	if maxCapacity == 0 {
+		maxCapacity = 2
+	}
+
+	if maxCapacity < minCapacity {
+		maxCapacity = minCapacity
+	}
+
+	clusterProperties := map[string]any{
+		"DBClusterIdentifier": manifest.Name,
+		"Engine":              engine.engine,
+		"DatabaseName":        getDatabaseName(manifest),
+		"MasterUsername":      getDatabaseUsername(manifest),
+		"MasterUserPassword": map[string]any{
+			"Fn::Sub": "{{resolve:secretsmanager:${DatabaseSecret}:SecretString:password}}",
+		},
+		"StorageEncrypted": true,
+		"Port":             engine.port,
+		"DBSubnetGroupName": map[string]any{
+			"Ref": "DatabaseSubnetGroup",
+		},
+		"VpcSecurityGroupIds": vpcSecurityGroupIds(manifest),
+		"ServerlessV2ScalingConfiguration": map[string]any{
+			"MinCapacity": minCapacity,
+			"MaxCapacity": maxCapacity,
+		},
+	}
+
+	if manifest.Database.Version != "" {
+		clusterProperties["EngineVersion"] = manifest.Database.Version
+	}
+
+	secretTemplate, _ := json.Marshal(map[string]string{
+		"username": getDatabaseUsername(manifest),
+	})
+
+	result := map[string]any{
+		// The secret is kept with the snapshots, as it holds their password.
+		"DatabaseSecret": map[string]any{
+			"Type":                "AWS::SecretsManager::Secret",
+			"DeletionPolicy":      "Retain",
+			"UpdateReplacePolicy": "Retain",
+			"Properties": map[string]any{
+				"Description": "Master credentials of the " + manifest.Name + " database",
+				"GenerateSecretString": map[string]any{
+					"SecretStringTemplate": string(secretTemplate),
+					"GenerateStringKey":    "password",
+					"PasswordLength":       32,
+					"ExcludeCharacters":    "\"@/\\'` ",
+				},
+			},
+		},
+		"DatabaseSubnetGroup": map[string]any{
+			"Type": "AWS::RDS::DBSubnetGroup",
+			"Properties": map[string]any{
+				"DBSubnetGroupDescription": "Subnets of the " + manifest.Name + " database",
+				"SubnetIds":                vpcSubnetIds(manifest),
+			},
+		},
+		"DatabaseCluster": map[string]any{
+			"Type":                "AWS::RDS::DBCluster",
+			"DeletionPolicy":      "Snapshot",
+			"UpdateReplacePolicy": "Snapshot",
+			"Properties":          clusterProperties,
+		},
+		"DatabaseInstance": map[string]any{
+			"Type": "AWS::RDS::DBInstance",
+			"Properties": map[string]any{
+				"DBInstanceClass": "db.serverless",
+				"Engine":          engine.engine,
+				"DBClusterIdentifier": map[string]any{
+					"Ref": "DatabaseCluster",
+				},
+			},
+		},
+	}
+
+	// The functions share the security groups of the cluster, so each group may reach it from itself.
+	for i, securityGroup := range vpcSecurityGroupIds(manifest) {
+		result["DatabaseIngress"+strconv.Itoa(i+1)] = map[string]any{
+			"Type": "AWS::EC2::SecurityGroupIngress",
+			"Properties": map[string]any{
+				"Description":           "Database access from the " + manifest.Name + " functions",
+				"GroupId":               securityGroup,
+				"SourceSecurityGroupId": securityGroup,
+				"IpProtocol":            "tcp",
+				"FromPort":              engine.port,
+				"ToPort":                engine.port,
+			},
+		}
+	}
+
+	return result
+}
+
+func databaseVariables(manifest *manifest.Manifest) map[string]any {
+	return map[string]any{
+		"DB_CONNECTION": databaseEngines[manifest.Database.Engine].connection,
+		"DB_HOST": map[string]any{
+			"Fn::GetAtt": []any{"DatabaseCluster", "Endpoint.Address"},
+		},
+		"DB_PORT": map[string]any{
+			"Fn::GetAtt": []any{"DatabaseCluster", "Endpoint.Port"},
+		},
+		"DB_DATABASE": getDatabaseName(manifest),
+		"DB_USERNAME": getDatabaseUsername(manifest),
+		"DB_PASSWORD_SECRET": map[string]any{
+			"Ref": "DatabaseSecret",
+		},
+	}
+}
@@ -0,0 +1,82 @@
+package provisioner
+
+import (
+	"hover/utils/manifest"
+	"regexp"
+	"strings"
+)
+
+type deadLetterQueue struct {
+	resourceName string
+	queueName    string
+	fifo         bool
+}
+
+func getDeadLetterQueues(stage *manifest.Manifest) map[string]deadLetterQueue {
+	result := map[string]deadLetterQueue{}
+
+	for queueFunctionName, queueConfiguration := range stage.Queue {
+		for _, queueName := range queueConfiguration.QueueNames() {
+			switch queueConfiguration.DeadLetterQueueMode() {
+			case manifest.DeadLetterQueuePerQueue:
+				result[queueName] = deadLetterQueue{
+					resourceName: logicalId(queueName) + "DeadLetterQueue",
+					queueName:    queueName + "-dlq",
+					fifo:         queueConfiguration.Fifo,
+				}
+			case manifest.DeadLetterQueueShared:
+				result[queueName] = deadLetterQueue{
+					resourceName: logicalId(queueFunctionName) + "WorkerDeadLetterQueue",
+					queueName:    queueFunctionName + "-dlq",
+					fifo:         queueConfiguration.Fifo,
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+func deadLetterQueues(manifest *manifest.Manifest) (map[string]any, map[string]any) {
+	resources := map[string]any{}
+	outputs := map[string]any{}
+
+	for _, queue := range getDeadLetterQueues(manifest) {
+		properties := map[string]any{
+			"QueueName": getSqsQueueName(manifest, queue.queueName, queue.fifo),
+			// Failed messages are kept for the maximum period to leave time to inspect them.
+			"MessageRetentionPeriod": 1209600,
+		}
+
+		if queue.fifo {
+			properties["FifoQueue"] = true
+		}
+
+		resources[queue.resourceName] = map[string]any{
+			"Type":       "AWS::SQS::Queue",
+			"Properties": properties,
+		}
+
+		outputs[queue.resourceName+"Url"] = map[string]any{
+			"Description": "Dead-Letter Queue URL (" + queue.queueName + ")",
+			"Value": map[string]any{
+				"Ref": queue.resourceName,
+			},
+		}
+	}
+
+	return resources, outputs
+}
+
+func GetDeadLetterQueueName(manifest *manifest.Manifest, queueName string) (string, bool) {
+	queue, ok := getDeadLetterQueues(manifest)[queueName]
+	if !ok {
+		return "", false
+	}
+
+	return getSqsQueueName(manifest, queue.queueName, queue.fifo), true
+}
+
+func deadLetterQueueVariableName(queueName string) string {
+	return "SQS_DLQ_" + strings.ToUpper(regexp.MustCompile("[^a-zA-Z0-9]+").ReplaceAllString(queueName, "_"))
+}
@@ -0,0 +1,221 @@
+package provisioner
+
+import (
+	"encoding/json"
+	"fmt"
+	route53Types "github.com/aws/aws-sdk-go-v2/service/route53/types"
+	"hover/aws"
+	"hover/utils/manifest"
+	"regexp"
+	"strings"
+)
+
+// The hosted zone ID AWS uses for all CloudFront distributions.
+const cloudFrontHostedZoneId = "Z2FDTNDATAQYW2"
+
+// A distribution has a single certificate, so domains are grouped by certificate.
+type distributionGroup struct {
+	resourceName string
+	outputName   string
+	certificate  string
+	domains      []string
+}
+
+func getDistributionGroups(manifest *manifest.Manifest) []distributionGroup {
+	primaryGroup := distributionGroup{
+		resourceName: "CFDistribution",
+		outputName:   "CDNDomain",
+		certificate:  manifest.HTTP.Certificate,
+	}
+
+	if primaryGroup.certificate == "" && len(manifest.HTTP.Domains) > 0 {
+		primaryGroup.certificate = manifest.HTTP.Domains[0].Certificate
+	}
+
+	groups := []distributionGroup{primaryGroup}
+
+	for _, domain := range manifest.HTTP.Domains {
+		certificate := domain.Certificate
+		if certificate == "" {
+			certificate = manifest.HTTP.Certificate
+		}
+
+		found := false
+
+		for i := range groups {
+			if groups[i].certificate == certificate {
+				groups[i].domains = append(groups[i].domains, domain.Name)
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			certificateId := logicalId(certificate[strings.LastIndex(certificate, "/")+1:])
+
+			groups = append(groups, distributionGroup{
+				resourceName: "CFDistribution" + certificateId,
+				outputName:   "CDNDomain" + certificateId,
+				certificate:  certificate,
+				domains:      []string{domain.Name},
+			})
+		}
+	}
+
+	return groups
+}
+
+func GetCDNDomainOutputName(manifest *manifest.Manifest, domain string) string {
+	for _, group := range getDistributionGroups(manifest) {
+		for _, groupDomain := range group.domains {
+			if groupDomain == domain {
+				return group.outputName
+			}
+		}
+	}
+
+	return "CDNDomain"
+}
+
+func redirectFunction(resourceName string, redirects map[string]string, manifest *manifest.Manifest) map[string]any {
+	redirectsJson, _ := json.Marshal(redirects)
+
+	code := fmt.Sprintf(`var redirects = %s;
+
+function handler(event) {
+    var request = event.request;
+    var host = request.headers.host ? request.headers.host.value.toLowerCase() : '';
+    var target = redirects[host];
+
+    if (!target) {
+        return request;
+    }
+
+    var query = [];
+
+    for (var key in request.querystring) {
+        var parameter = request.querystring[key];
+
+        if (parameter.multiValue) {
+            for (var i = 0; i < parameter.multiValue.length; i++) {
+                query.push(key + '=' + parameter.multiValue[i].value);
+            }
+        } else {
+            query.push(key + '=' + parameter.value);
+        }
+    }
+
+    return {
+        statusCode: 301,
+        statusDescription: 'Moved Permanently',
+        headers: {
+            location: { value: 'https://' + target + request.uri + (query.length ? '?' + query.join('&') : '') }
+        }
+    };
+}
+`, redirectsJson)
+
+	return map[string]any{
+		resourceName: map[string]any{
+			"Type": "AWS::CloudFront::Function",
+			"Properties": map[string]any{
+				"Name":         manifest.Name + "-redirects",
+				"AutoPublish":  true,
+				"FunctionCode": code,
+				"FunctionConfig": map[string]any{
+					"Comment": "Domain redirects of " + manifest.Name,
+					"Runtime": "cloudfront-js-1.0",
+				},
+			},
+		},
+	}
+}
+
+func dnsRecords(manifest *manifest.Manifest) map[string]any {
+	result := map[string]any{}
+
+	for _, group := range getDistributionGroups(manifest) {
+		for _, domain := range group.domains {
+			for _, recordType := range []string{"A", "AAAA"} {
+				properties := map[string]any{
+					"Name": domain,
+					"Type": recordType,
+					"AliasTarget": map[string]any{
+						"DNSName": map[string]any{
+							"Fn::GetAtt": []any{group.resourceName, "DomainName"},
+						},
+						"HostedZoneId": cloudFrontHostedZoneId,
+					},
+				}
+
+				if isHostedZoneId(manifest.HTTP.HostedZone) {
+					properties["HostedZoneId"] = manifest.HTTP.HostedZone
+				} else {
+					properties["HostedZoneName"] = strings.TrimSuffix(manifest.HTTP.HostedZone, ".") + "."
+				}
+
+				result[logicalId(domain)+recordType+"Record"] = map[string]any{
+					"Type":       "AWS::Route53::RecordSet",
+					"Properties": properties,
+				}
+			}
+		}
+	}
+
+	return result
+}
+
+func GetHostedZoneId(manifest *manifest.Manifest, aws *aws.Aws) (string, error) {
+	if isHostedZoneId(manifest.HTTP.HostedZone) {
+		return manifest.HTTP.HostedZone, nil
+	}
+
+	name := strings.TrimSuffix(manifest.HTTP.HostedZone, ".") + "."
+
+	hostedZones, err := aws.GetHostedZones(&name)
+	if err != nil {
+		return "", fmt.Errorf("unable to list the hosted zones. Error: %w", err)
+	}
+
+	for _, hostedZone := range hostedZones {
+		if strings.EqualFold(*hostedZone.Name, name) && (hostedZone.Config == nil || !hostedZone.Config.PrivateZone) {
+			return strings.TrimPrefix(*hostedZone.Id, "/hostedzone/"), nil
+		}
+	}
+
+	return "", fmt.Errorf("unable to find the `%s` hosted zone", manifest.HTTP.HostedZone)
+}
+
+func GetDnsRecordTarget(hostedZoneId string, domain string, aws *aws.Aws) (string, error) {
+	record, err := aws.GetResourceRecordSet(&hostedZoneId, &domain, route53Types.RRTypeA)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the DNS record of `%s`. Error: %w", domain, err)
+	}
+
+	if record == nil || record.AliasTarget == nil {
+		return "", nil
+	}
+
+	return strings.TrimSuffix(*record.AliasTarget.DNSName, "."), nil
+}
+
+func isHostedZoneId(hostedZone string) bool {
+	return strings.HasPrefix(hostedZone, "Z") && !strings.Contains(hostedZone, ".")
+}
+
+func logicalId(value string) string {
+	value = strings.ReplaceAll(value, "*", "wildcard")
+
+	var result string
+
+	for _, part := range regexp.MustCompile("[^a-zA-Z0-9]+").Split(value, -1) {
+		if part == "" {
+			continue
+		}
+
+		result += strings.ToUpper(part[:1]) + part[1:]
+	}
+
+	return result
+}
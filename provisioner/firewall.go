@@ -0,0 +1,229 @@
+package provisioner
+
+import (
+	"encoding/json"
+	"fmt"
+	"golang.org/x/exp/maps"
+	"hover/aws"
+	"hover/utils"
+	"hover/utils/manifest"
+	"strconv"
+	"strings"
+)
+
+// CloudFront web ACLs can only be created in us-east-1.
+const firewallRegion = "us-east-1"
+
+var managedRuleGroups = map[string]string{
+	"core-rule-set":    "AWSManagedRulesCommonRuleSet",
+	"known-bad-inputs": "AWSManagedRulesKnownBadInputsRuleSet",
+	"sql-injection":    "AWSManagedRulesSQLiRuleSet",
+	"ip-reputation":    "AWSManagedRulesAmazonIpReputationList",
+	"php":              "AWSManagedRulesPHPRuleSet",
+}
+
+func GetFirewallStackName(stageName string) string {
+	return stageName + "-firewall"
+}
+
+func provisionFirewall(manifest *manifest.Manifest, aws *aws.Aws) (string, error) {
+	utils.PrintStep("Provisioning the firewall")
+
+	stackName := GetFirewallStackName(manifest.Name)
+
+	stack, _, err := deployStack(stackName, getFirewallTemplate(manifest), &manifest.Auth.StackRole, nil, aws.ForRegion(firewallRegion))
+	if err != nil {
+		return "", err
+	}
+
+	webAclArn := GetStackOutput(stack, "WebACLArn")
+	if webAclArn == "" {
+		return "", fmt.Errorf("unable to find the web ACL of stack '%s'", stackName)
+	}
+
+	return webAclArn, nil
+}
+
+func DeleteFirewall(manifest *manifest.Manifest, aws *aws.Aws) error {
+	stackName := GetFirewallStackName(manifest.Name)
+	firewallAws := aws.ForRegion(firewallRegion)
+
+	_, err := firewallAws.GetStack(&stackName)
+	if err != nil {
+		if firewallAws.StackDoesntExist(err) {
+			return nil
+		}
+
+		return err
+	}
+
+	fmt.Println("Deleting the firewall stack")
+
+	_, err = firewallAws.DeleteStack(&stackName)
+
+	return err
+}
+
+func getFirewallTemplate(manifest *manifest.Manifest) *string {
+	resources := map[string]any{}
+	var rules []any
+
+	addIpSetRules := func(prefix string, addresses []string, action string) {
+		var ipv4, ipv6 []string
+
+		for _, address := range addresses {
+			if strings.Contains(address, ":") {
+				ipv6 = append(ipv6, address)
+			} else {
+				ipv4 = append(ipv4, address)
+			}
+		}
+
+		ipSets := []struct {
+			version   string
+			addresses []string
+		}{
+			{"IPv4", ipv4},
+			{"IPv6", ipv6},
+		}
+
+		for _, ipSet := range ipSets {
+			if len(ipSet.addresses) == 0 {
+				continue
+			}
+
+			resourceName := prefix + ipSet.version + "Set"
+			ruleName := strings.ToLower(prefix + "-" + ipSet.version)
+
+			resources[resourceName] = map[string]any{
+				"Type": "AWS::WAFv2::IPSet",
+				"Properties": map[string]any{
+					"Name":             manifest.Name + "-" + ruleName,
+					"Scope":            "CLOUDFRONT",
+					"IPAddressVersion": strings.ToUpper(ipSet.version),
+					"Addresses":        ipSet.addresses,
+				},
+			}
+
+			rules = append(rules, firewallRule(manifest, ruleName, map[string]any{
+				"IPSetReferenceStatement": map[string]any{
+					"Arn": map[string]any{
+						"Fn::GetAtt": []any{resourceName, "Arn"},
+					},
+				},
+			}, map[string]any{
+				"Action": map[string]any{action: map[string]any{}},
+			}))
+		}
+	}
+
+	addIpSetRules("Deny", manifest.Firewall.DenyIPs, "Block")
+
+	for i, rateLimit := range manifest.Firewall.RateLimits {
+		statement := map[string]any{
+			"Limit":            rateLimit.Limit,
+			"AggregateKeyType": "IP",
+		}
+
+		if rateLimit.Path != "" {
+			statement["ScopeDownStatement"] = map[string]any{
+				"ByteMatchStatement": map[string]any{
+					"SearchString": rateLimit.Path,
+					"FieldToMatch": map[string]any{
+						"UriPath": map[string]any{},
+					},
+					"TextTransformations": []any{
+						map[string]any{"Priority": 0, "Type": "NONE"},
+					},
+					"PositionalConstraint": "STARTS_WITH",
+				},
+			}
+		}
+
+		rules = append(rules, firewallRule(manifest, "rate-limit-"+strconv.Itoa(i+1), map[string]any{
+			"RateBasedStatement": statement,
+		}, map[string]any{
+			"Action": map[string]any{"Block": map[string]any{}},
+		}))
+	}
+
+	for _, ruleGroup := range manifest.Firewall.ManagedRules {
+		ruleGroupName := ruleGroup
+		if name, ok := managedRuleGroups[ruleGroup]; ok {
+			ruleGroupName = name
+		}
+
+		rules = append(rules, firewallRule(manifest, ruleGroupName, map[string]any{
+			"ManagedRuleGroupStatement": map[string]any{
+				"VendorName": "AWS",
+				"Name":       ruleGroupName,
+			},
+		}, map[string]any{
+			"OverrideAction": map[string]any{"None": map[string]any{}},
+		}))
+	}
+
+	// Other IPs fall through to the default action, which blocks them.
+	addIpSetRules("Allow", manifest.Firewall.AllowIPs, "Allow")
+
+	defaultAction := "Allow"
+	if len(manifest.Firewall.AllowIPs) > 0 {
+		defaultAction = "Block"
+	}
+
+	for i, rule := range rules {
+		rule.(map[string]any)["Priority"] = i
+	}
+
+	resources["WebACL"] = map[string]any{
+		"Type": "AWS::WAFv2::WebACL",
+		"Properties": map[string]any{
+			"Name":  manifest.Name + "-firewall",
+			"Scope": "CLOUDFRONT",
+			"DefaultAction": map[string]any{
+				defaultAction: map[string]any{},
+			},
+			"Rules":            rules,
+			"VisibilityConfig": firewallVisibilityConfig(manifest.Name + "-firewall"),
+		},
+	}
+
+	template := map[string]any{
+		"AWSTemplateFormatVersion": "2010-09-09",
+		"Resources":                resources,
+		"Outputs": map[string]any{
+			"WebACLArn": map[string]any{
+				"Description": "Firewall Web ACL",
+				"Value": map[string]any{
+					"Fn::GetAtt": []any{"WebACL", "Arn"},
+				},
+			},
+		},
+	}
+
+	jsonPrint, _ := json.MarshalIndent(template, "", " ")
+
+	jsonPrintString := string(jsonPrint)
+
+	return &jsonPrintString
+}
+
+func firewallRule(manifest *manifest.Manifest, name string, statement map[string]any, action map[string]any) map[string]any {
+	rule := map[string]any{
+		"Name":             name,
+		"Statement":        statement,
+		"VisibilityConfig": firewallVisibilityConfig(manifest.Name + "-" + name),
+	}
+
+	maps.Copy(rule, action)
+
+	return rule
+}
+
+func firewallVisibilityConfig(metricName string) map[string]any {
+	return map[string]any{
+		"SampledRequestsEnabled":   true,
+		"CloudWatchMetricsEnabled": true,
+		"MetricName":               metricName,
+	}
+}
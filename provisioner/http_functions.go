@@ -0,0 +1,175 @@
+package provisioner
+
+import (
+	"golang.org/x/exp/maps"
+	"hover/utils/manifest"
+	"sort"
+	"strings"
+)
+
+func getHttpFunctionNames(manifest *manifest.Manifest) []string {
+	names := maps.Keys(manifest.HTTP.Functions)
+
+	sort.Strings(names)
+
+	return names
+}
+
+func GetHttpLambdaFunctionNames(manifest *manifest.Manifest) []string {
+	names := []string{GetLambdaFunctionName(manifest.Name, "http")}
+
+	for _, name := range getHttpFunctionNames(manifest) {
+		names = append(names, GetLambdaFunctionName(manifest.Name, name+"-http"))
+	}
+
+	return names
+}
+
+func getHttpFunctionResourceName(name string) string {
+	return logicalId(name) + "HTTPLambda"
+}
+
+func httpFunctions(imageUri string, manifest *manifest.Manifest) map[string]any {
+	result := map[string]any{}
+
+	for _, name := range getHttpFunctionNames(manifest) {
+		function := manifest.HTTP.Functions[name]
+		resourceName := getHttpFunctionResourceName(name)
+		aliasResourceName := resourceName + "LiveAlias"
+
+		maps.Copy(result, lambdaFunction(resourceName, name+"-http", imageUri, manifest, function.Timeout, function.Memory, function.Concurrency))
+		maps.Copy(result, lambdaAlias(resourceName, aliasResourceName))
+
+		if manifest.UsesFunctionUrl() {
+			maps.Copy(result, functionUrl(logicalId(name)+"FunctionUrl", resourceName, aliasResourceName))
+
+			continue
+		}
+
+		integrationResourceName := resourceName + "Integration"
+
+		result[integrationResourceName] = map[string]any{
+			"Type": "AWS::ApiGatewayV2::Integration",
+			"DependsOn": []any{
+				aliasResourceName,
+			},
+			"Properties": map[string]any{
+				"ApiId": map[string]any{
+					"Ref": "ApiGateway",
+				},
+				"IntegrationType":      "AWS_PROXY",
+				"IntegrationMethod":    "POST",
+				"PayloadFormatVersion": "2.0",
+				"IntegrationUri": map[string]any{
+					"Fn::Sub": []any{
+						"arn:aws:apigateway:${AWS::Region}:lambda:path/2015-03-31/functions/${functionArn}:live/invocations",
+						map[string]any{
+							"functionArn": map[string]any{
+								"Fn::GetAtt": []any{
+									resourceName,
+									"Arn",
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+
+		result[resourceName+"InvokePermission"] = map[string]any{
+			"Type": "AWS::Lambda::Permission",
+			"DependsOn": []any{
+				aliasResourceName,
+			},
+			"Properties": map[string]any{
+				"Action": "lambda:InvokeFunction",
+				"FunctionName": map[string]any{
+					"Fn::Join": []any{
+						":",
+						[]any{
+							map[string]any{
+								"Ref": resourceName,
+							},
+							"live",
+						},
+					},
+				},
+				"Principal": "apigateway.amazonaws.com",
+				"SourceArn": map[string]any{
+					"Fn::Sub": "arn:aws:execute-api:${AWS::Region}:${AWS::AccountId}:${ApiGateway}/*",
+				},
+			},
+		}
+
+		for _, path := range function.Paths {
+			routeKey := "ANY " + path
+
+			// API Gateway matches nested paths using a greedy path variable.
+			if strings.HasSuffix(path, "/*") {
+				routeKey = "ANY " + strings.TrimSuffix(path, "*") + "{proxy+}"
+			}
+
+			result["ApiGatewayRoute"+logicalId(routeKey)] = map[string]any{
+				"Type": "AWS::ApiGatewayV2::Route",
+				"DependsOn": []any{
+					integrationResourceName,
+				},
+				"Properties": map[string]any{
+					"ApiId": map[string]any{
+						"Ref": "ApiGateway",
+					},
+					"RouteKey":          routeKey,
+					"AuthorizationType": "NONE",
+					"Target": map[string]any{
+						"Fn::Join": []any{
+							"/",
+							[]any{
+								"integrations",
+								map[string]any{
+									"Ref": integrationResourceName,
+								},
+							},
+						},
+					},
+				},
+			}
+		}
+	}
+
+	return result
+}
+
+func httpFunctionDistributionConfig(distributionConfig map[string]any, manifest *manifest.Manifest) {
+	if !manifest.UsesFunctionUrl() {
+		return
+	}
+
+	defaultCacheBehavior := distributionConfig["DefaultCacheBehavior"].(map[string]any)
+
+	for _, name := range getHttpFunctionNames(manifest) {
+		function := manifest.HTTP.Functions[name]
+		originId := name + "-gateway"
+
+		distributionConfig["Origins"] = append(
+			distributionConfig["Origins"].([]any),
+			functionUrlOrigin(originId, logicalId(name)+"FunctionUrl", function.Timeout),
+		)
+
+		var targetOriginId any = originId
+
+		if manifest.HTTP.MaintenancePage != "" {
+			targetOriginId = map[string]any{
+				"Fn::If": []any{"MaintenanceModeEnabled", "assets-bucket", originId},
+			}
+		}
+
+		for _, path := range function.Paths {
+			cacheBehavior := maps.Clone(defaultCacheBehavior)
+
+			cacheBehavior["PathPattern"] = path
+			cacheBehavior["TargetOriginId"] = targetOriginId
+
+			distributionConfig["CacheBehaviors"] = append(distributionConfig["CacheBehaviors"].([]any), cacheBehavior)
+		}
+	}
+}
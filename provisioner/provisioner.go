@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	"github.com/aws/smithy-go/ptr"
 	"github.com/pterm/pterm"
 	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 	"hover/aws"
 	"hover/utils"
 	"hover/utils/manifest"
@@ -15,18 +17,78 @@ import (
 	"time"
 )
 
+const defaultAccessLogFormat = `{"requestId":"$context.requestId","ip":"$context.identity.sourceIp","requestTime":"$context.requestTime","httpMethod":"$context.httpMethod","routeKey":"$context.routeKey","path":"$context.path","status":"$context.status","protocol":"$context.protocol","responseLength":"$context.responseLength","integrationLatency":"$context.integrationLatency","integrationError":"$context.integrationErrorMessage"}`
+
 func Provision(manifest *manifest.Manifest, imageUri string, aws *aws.Aws) (*types.Stack, *cloudformation.DescribeStackResourcesOutput, error) {
+	webAclArn := ""
+
+	if manifest.Firewall.Enabled() {
+		arn, err := provisionFirewall(manifest, aws)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		webAclArn = arn
+	}
+
+	var currentVpc *existingVpc
+
+	// CloudFormation can't look up the ID and route tables of an existing VPC.
+	if manifest.VPC.Enabled() && !manifest.VPC.Create {
+		existing, err := getExistingVpc(manifest, aws)
+		if err != nil && len(manifest.VPC.Endpoints) > 0 {
+			return nil, nil, err
+		}
+
+		if err != nil {
+			utils.PrintWarning(err.Error())
+		}
+
+		currentVpc = existing
+	}
+
 	utils.PrintStep("Provisioning the stack")
 
-	template := getTemplate(manifest, imageUri, manifest.BuildDetails.Hash)
-	currentStack, err := getCloudFormationStack(manifest.Name, aws)
+	template := getTemplate(manifest, imageUri, manifest.BuildDetails.Hash, webAclArn, currentVpc, getPublishedFunctions(manifest, aws))
+	currentStack, err := GetCloudFormationStack(manifest.Name, aws)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	stack, resources, err := deployStack(manifest.Name, template, &manifest.Auth.StackRole, getParameters(manifest, &currentStack), aws)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	err = verifyVpcEndpoints(manifest, currentVpc)
+	if err != nil {
+		utils.PrintWarning(err.Error())
+	}
+
+	err = ConfigureS3Triggers(manifest, &currentStack, aws)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !manifest.Firewall.Enabled() && GetStackOutput(&currentStack, "WebACLArn") != "" {
+		err = DeleteFirewall(manifest, aws)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return stack, resources, nil
+}
+
+func deployStack(name string, template *string, roleArn *string, parameters []types.Parameter, aws *aws.Aws) (*types.Stack, *cloudformation.DescribeStackResourcesOutput, error) {
+	currentStack, err := GetCloudFormationStack(name, aws)
 	if err != nil {
 		return nil, nil, err
 	}
 
 	if currentStack.StackId != nil {
 		if currentStack.StackStatus == types.StackStatusRollbackComplete {
-			_, err = aws.DeleteStack(&manifest.Name)
+			_, err = aws.DeleteStack(&name)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -34,9 +96,9 @@ func Provision(manifest *manifest.Manifest, imageUri string, aws *aws.Aws) (*typ
 			return nil, nil, fmt.Errorf("a failed stack is being deleted. Try again in a bit")
 		}
 
-		stackResources, _ := aws.GetStackResources(&manifest.Name)
+		stackResources, _ := aws.GetStackResources(&name)
 
-		_, err = aws.UpdateStack(&manifest.Name, template)
+		_, err = aws.UpdateStack(&name, template, parameters)
 		if err != nil {
 			if strings.Contains(err.Error(), "No updates are to be performed") {
 				fmt.Println("No stack changes to perform")
@@ -47,18 +109,67 @@ func Provision(manifest *manifest.Manifest, imageUri string, aws *aws.Aws) (*typ
 			}
 		}
 	} else {
-		_, err = aws.CreateStack(&manifest.Name, template, &manifest.Auth.StackRole)
+		_, err = aws.CreateStack(&name, template, roleArn, parameters)
 		if err != nil {
 			return nil, nil, err
 		}
 	}
 
+	return waitForStack(name, aws)
+}
+
+func SetMaintenanceMode(manifest *manifest.Manifest, enabled bool, aws *aws.Aws) error {
+	stack, err := aws.GetStack(&manifest.Name)
+	if err != nil {
+		return err
+	}
+
+	currentValue, exists := getParameterValue(&stack, "MaintenanceMode")
+	if !exists {
+		return fmt.Errorf("stage '%s' has no maintenance page. Set `http.maintenance-page` in the manifest and deploy the stage first", manifest.Name)
+	}
+
+	if currentValue == strconv.FormatBool(enabled) {
+		fmt.Println("No stack changes to perform")
+
+		return nil
+	}
+
+	var parameters []types.Parameter
+
+	for _, parameter := range stack.Parameters {
+		if *parameter.ParameterKey == "MaintenanceMode" {
+			continue
+		}
+
+		parameters = append(parameters, types.Parameter{
+			ParameterKey:     parameter.ParameterKey,
+			UsePreviousValue: ptr.Bool(true),
+		})
+	}
+
+	parameters = append(parameters, types.Parameter{
+		ParameterKey:   ptr.String("MaintenanceMode"),
+		ParameterValue: ptr.String(strconv.FormatBool(enabled)),
+	})
+
+	_, err = aws.UpdateStackParameters(&manifest.Name, parameters)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = waitForStack(manifest.Name, aws)
+
+	return err
+}
+
+func waitForStack(name string, aws *aws.Aws) (*types.Stack, *cloudformation.DescribeStackResourcesOutput, error) {
 	spinner, _ := pterm.DefaultSpinner.Start("Updating the CloudFormation stack...")
 
 	time.Sleep(5 * time.Second)
 
 	for {
-		results, err := aws.GetStack(&manifest.Name)
+		results, err := aws.GetStack(&name)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -68,7 +179,7 @@ func Provision(manifest *manifest.Manifest, imageUri string, aws *aws.Aws) (*typ
 			types.StackStatusCreateComplete,
 			types.StackStatusUpdateComplete,
 			types.StackStatusUpdateRollbackComplete:
-			resources, _ := aws.GetStackResources(&manifest.Name)
+			resources, _ := aws.GetStackResources(&name)
 			spinner.Stop()
 			return &results, resources, nil
 		case
@@ -76,15 +187,43 @@ func Provision(manifest *manifest.Manifest, imageUri string, aws *aws.Aws) (*typ
 			types.StackStatusCreateInProgress,
 			types.StackStatusUpdateCompleteCleanupInProgress:
 		default:
-			return nil, nil, printError(manifest, aws)
+			return nil, nil, printError(name, aws)
+		}
+
+		time.Sleep(5 * time.Second)
+	}
+}
+
+func WaitForStackDeletion(name string, aws *aws.Aws) error {
+	spinner, _ := pterm.DefaultSpinner.Start("Waiting for the CloudFormation stack to be deleted...")
+
+	for {
+		results, err := aws.GetStack(&name)
+		if err != nil {
+			spinner.Stop()
+
+			if aws.StackDoesntExist(err) {
+				return nil
+			}
+
+			return err
+		}
+
+		switch results.StackStatus {
+		case types.StackStatusDeleteComplete:
+			spinner.Stop()
+			return nil
+		case types.StackStatusDeleteFailed:
+			spinner.Stop()
+			return printError(name, aws)
 		}
 
 		time.Sleep(5 * time.Second)
 	}
 }
 
-func printError(manifest *manifest.Manifest, aws *aws.Aws) error {
-	events, _ := aws.GetStackEvents(&manifest.Name)
+func printError(name string, aws *aws.Aws) error {
+	events, _ := aws.GetStackEvents(&name)
 
 	for _, event := range events.StackEvents {
 		if event.ResourceStatus == types.ResourceStatusUpdateInProgress && *event.ResourceType == "AWS::CloudFormation::Stack" {
@@ -104,10 +243,10 @@ func printError(manifest *manifest.Manifest, aws *aws.Aws) error {
 		}
 	}
 
-	return fmt.Errorf("stack '" + manifest.Name + "' provisioning failed")
+	return fmt.Errorf("stack '" + name + "' provisioning failed")
 }
 
-func getCloudFormationStack(name string, aws *aws.Aws) (types.Stack, error) {
+func GetCloudFormationStack(name string, aws *aws.Aws) (types.Stack, error) {
 	response, err := aws.GetStack(&name)
 	if err == nil {
 		return response, nil
@@ -120,11 +259,53 @@ func getCloudFormationStack(name string, aws *aws.Aws) (types.Stack, error) {
 	return types.Stack{}, err
 }
 
+func getParameters(manifest *manifest.Manifest, currentStack *types.Stack) []types.Parameter {
+	var parameters []types.Parameter
+
+	if manifest.HTTP.MaintenancePage != "" {
+		// Maintenance mode is toggled outside deployments, so its current value is kept.
+		if _, exists := getParameterValue(currentStack, "MaintenanceMode"); exists {
+			parameters = append(parameters, types.Parameter{
+				ParameterKey:     ptr.String("MaintenanceMode"),
+				UsePreviousValue: ptr.Bool(true),
+			})
+		}
+	}
+
+	return parameters
+}
+
+func getParameterValue(stack *types.Stack, key string) (string, bool) {
+	for _, parameter := range stack.Parameters {
+		if *parameter.ParameterKey == key {
+			return *parameter.ParameterValue, true
+		}
+	}
+
+	return "", false
+}
+
+func IsInMaintenanceMode(stack *types.Stack) bool {
+	value, _ := getParameterValue(stack, "MaintenanceMode")
+
+	return value == "true"
+}
+
+func GetStackOutput(stack *types.Stack, key string) string {
+	for _, output := range stack.Outputs {
+		if *output.OutputKey == key {
+			return *output.OutputValue
+		}
+	}
+
+	return ""
+}
+
 func GetLambdaFunctionName(stageName string, functionName string) string {
 	return stageName + "-" + functionName
 }
 
-func getTemplate(manifest *manifest.Manifest, imageUri string, manifestHash string) *string {
+func getTemplate(manifest *manifest.Manifest, imageUri string, manifestHash string, webAclArn string, existingVpc *existingVpc, publishedFunctions []string) *string {
 	template := map[string]any{
 		"AWSTemplateFormatVersion": "2010-09-09",
 		"Resources":                map[string]any{},
@@ -134,6 +315,26 @@ func getTemplate(manifest *manifest.Manifest, imageUri string, manifestHash stri
 	var resources map[string]any
 	var outputs map[string]any
 
+	if manifest.HTTP.MaintenancePage != "" {
+		template["Parameters"] = map[string]any{
+			"MaintenanceMode": map[string]any{
+				"Type":          "String",
+				"Default":       "false",
+				"AllowedValues": []string{"true", "false"},
+				"Description":   "Serve the maintenance page for all non-asset requests",
+			},
+		}
+
+		template["Conditions"] = map[string]any{
+			"MaintenanceModeEnabled": map[string]any{
+				"Fn::Equals": []any{
+					map[string]any{"Ref": "MaintenanceMode"},
+					"true",
+				},
+			},
+		}
+	}
+
 	resources = map[string]any{}
 
 	outputs = map[string]any{
@@ -144,22 +345,158 @@ func getTemplate(manifest *manifest.Manifest, imageUri string, manifestHash stri
 
 	maps.Copy(resources, lambdaFunction("HTTPLambda", "http", imageUri, manifest, manifest.HTTP.Timeout, manifest.HTTP.Memory, manifest.HTTP.Concurrency))
 	maps.Copy(resources, lambdaAlias("HTTPLambda", "HTTPLambdaLiveAlias"))
-	maps.Copy(resources, warmer("HTTPLambda", "HTTPLambdaLiveAlias", manifest))
-	maps.Copy(resources, apiGateway("HTTPLambda", "HTTPLambdaLiveAlias", manifest))
+
+	if !manifest.UsesProvisionedConcurrency() && manifest.WarmContainers() > 0 {
+		maps.Copy(resources, warmer("HTTPLambda", "HTTPLambdaLiveAlias", manifest))
+	}
+
+	if manifest.UsesFunctionUrl() {
+		maps.Copy(resources, functionUrl("FunctionUrl", "HTTPLambda", "HTTPLambdaLiveAlias"))
+	} else {
+		maps.Copy(resources, apiGateway("HTTPLambda", "HTTPLambdaLiveAlias", manifest))
+	}
+
+	maps.Copy(resources, httpFunctions(imageUri, manifest))
+
 	maps.Copy(resources, lambdaFunction("CliLambda", "cli", imageUri, manifest, manifest.Cli.Timeout, manifest.Cli.Memory, manifest.Cli.Concurrency))
 	maps.Copy(resources, lambdaAlias("CliLambda", "CliLambdaLiveAlias"))
-	maps.Copy(resources, scheduler("CliLambda", manifest))
-	maps.Copy(resources, cloudFrontDistribution("ApiGateway", manifest))
+
+	if manifest.UsesScheduler() {
+		maps.Copy(resources, scheduler("CliLambda", manifest))
+	}
+
+	maps.Copy(resources, schedules("CliLambda", manifest))
+	maps.Copy(resources, triggers("CliLambda", manifest))
+
+	maps.Copy(resources, cloudFrontDistribution(manifest, webAclArn))
+
+	if manifest.HTTP.HostedZone != "" {
+		maps.Copy(resources, dnsRecords(manifest))
+	}
+
+	if manifest.Websocket != nil {
+		maps.Copy(resources, lambdaFunction("WebsocketLambda", "websocket", imageUri, manifest, manifest.Websocket.Timeout, manifest.Websocket.Memory, manifest.Websocket.Concurrency))
+		maps.Copy(resources, lambdaAlias("WebsocketLambda", "WebsocketLambdaLiveAlias"))
+		maps.Copy(resources, websocketApi("WebsocketLambda", "WebsocketLambdaLiveAlias", manifest))
+
+		outputs["WebsocketEndpoint"] = map[string]any{
+			"Description": "WebSocket Endpoint",
+			"Value": map[string]any{
+				"Fn::Sub": "wss://${WebsocketApi}.execute-api.${AWS::Region}.${AWS::URLSuffix}/" + websocketStageName,
+			},
+		}
+	}
+
+	if manifest.UsesDynamoDbCache() {
+		maps.Copy(resources, cacheTable(manifest))
+
+		outputs["CacheTable"] = map[string]any{
+			"Description": "Cache Table",
+			"Value": map[string]any{
+				"Ref": "CacheTable",
+			},
+		}
+	}
+
+	if manifest.Storage != nil {
+		maps.Copy(resources, storageBucket(manifest))
+
+		outputs["StorageBucket"] = map[string]any{
+			"Description": "Storage Bucket",
+			"Value": map[string]any{
+				"Ref": "StorageBucket",
+			},
+		}
+	}
+
+	if manifest.VPC.Create {
+		maps.Copy(resources, vpc(manifest))
+
+		outputs["VpcId"] = map[string]any{
+			"Description": "VPC ID",
+			"Value": map[string]any{
+				"Ref": "Vpc",
+			},
+		}
+	} else if existingVpc != nil {
+		var subnetIds, routeTableIds []any
+
+		for _, subnetId := range existingVpc.endpointSubnetIds {
+			subnetIds = append(subnetIds, subnetId)
+		}
+
+		for _, routeTableId := range existingVpc.routeTableIds {
+			routeTableIds = append(routeTableIds, routeTableId)
+		}
+
+		maps.Copy(resources, vpcEndpoints(manifest, existingVpc.id, subnetIds, routeTableIds))
+	}
+
+	if manifest.Database != nil {
+		maps.Copy(resources, database(manifest))
+
+		outputs["DatabaseEndpoint"] = map[string]any{
+			"Description": "Database Endpoint",
+			"Value": map[string]any{
+				"Fn::GetAtt": []any{"DatabaseCluster", "Endpoint.Address"},
+			},
+		}
+	}
+
+	if manifest.Redis != nil {
+		maps.Copy(resources, redis(manifest))
+
+		outputs["RedisEndpoint"] = map[string]any{
+			"Description": "Redis Endpoint",
+			"Value": map[string]any{
+				"Fn::GetAtt": []any{"RedisCache", "Endpoint.Address"},
+			},
+		}
+	}
 
 	for queueFunctionName, queueConfiguration := range manifest.Queue {
 		maps.Copy(resources, lambdaFunction(queueFunctionName+"QueueLambda", queueFunctionName+"-queue", imageUri, manifest, queueConfiguration.Timeout, queueConfiguration.Memory, queueConfiguration.Concurrency))
 		maps.Copy(resources, lambdaAlias(queueFunctionName+"QueueLambda", queueFunctionName+"LambdaLiveAlias"))
 
-		for _, queueName := range queueConfiguration.Queues {
-			maps.Copy(resources, queue(queueFunctionName+"QueueLambda", queueName+"Queue", queueName, manifest, queueConfiguration.Timeout+10))
+		for _, sqsQueue := range queueConfiguration.Queues {
+			maps.Copy(resources, queue(queueFunctionName+"QueueLambda", sqsQueue.Name+"Queue", sqsQueue, manifest, queueConfiguration.Timeout+10, queueConfiguration))
+		}
+	}
+
+	if webAclArn != "" {
+		outputs["WebACLArn"] = map[string]any{
+			"Value": webAclArn,
+		}
+	}
+
+	// Recorded so notifications can be removed from buckets that are no longer used.
+	if buckets := getS3TriggerBuckets(manifest); len(buckets) > 0 {
+		outputs["S3TriggerBuckets"] = map[string]any{
+			"Description": "S3 Trigger Buckets",
+			"Value":       strings.Join(buckets, ","),
 		}
 	}
 
+	for _, schedule := range getConcurrencySchedules(manifest) {
+		if slices.Contains(publishedFunctions, schedule.functionName) {
+			maps.Copy(resources, provisionedConcurrencySchedule(schedule))
+		}
+	}
+
+	// Recorded so the next deployment only updates the functions that changed.
+	if provisionedConcurrency := getStaticProvisionedConcurrency(manifest); len(provisionedConcurrency) > 0 {
+		value, _ := json.Marshal(provisionedConcurrency)
+
+		outputs["ProvisionedConcurrency"] = map[string]any{
+			"Value": string(value),
+		}
+	}
+
+	deadLetterQueueResources, deadLetterQueueOutputs := deadLetterQueues(manifest)
+
+	maps.Copy(resources, deadLetterQueueResources)
+	maps.Copy(outputs, deadLetterQueueOutputs)
+
 	maps.Copy(outputs, map[string]any{
 		"StageName": map[string]any{
 			"Description": "Stage Name",
@@ -169,13 +506,21 @@ func getTemplate(manifest *manifest.Manifest, imageUri string, manifestHash stri
 			"Description": "Build ID",
 			"Value":       manifest.BuildDetails.Id,
 		},
-		"CDNDomain": map[string]any{
-			"Description": "CDN Domain",
+	})
+
+	for _, group := range getDistributionGroups(manifest) {
+		description := "CDN Domain"
+		if group.resourceName != "CFDistribution" {
+			description = "CDN Domain (" + strings.Join(group.domains, ", ") + ")"
+		}
+
+		outputs[group.outputName] = map[string]any{
+			"Description": description,
 			"Value": map[string]any{
-				"Fn::GetAtt": []string{"CFDistribution", "DomainName"},
+				"Fn::GetAtt": []string{group.resourceName, "DomainName"},
 			},
-		},
-	})
+		}
+	}
 
 	template["Resources"] = resources
 	template["Outputs"] = outputs
@@ -195,52 +540,15 @@ func lambdaFunction(resourceName string, functionName string, imageUri string, m
 				"FunctionName": GetLambdaFunctionName(manifest.Name, functionName),
 				"Role":         manifest.Auth.LambdaRole,
 				"Environment": map[string]any{
-					"Variables": map[string]any{
-						"SQS_PREFIX": map[string]any{
-							"Fn::Join": []any{
-								"",
-								[]any{
-									"https://",
-									"sqs.",
-									map[string]any{
-										"Ref": "AWS::Region",
-									},
-									".",
-									map[string]any{
-										"Ref": "AWS::URLSuffix",
-									},
-									"/",
-									map[string]any{
-										"Ref": "AWS::AccountId",
-									},
-								},
-							},
-						},
-						"ASSET_URL": map[string]any{
-							"Fn::Join": []any{"/",
-								[]any{
-									"assets",
-									manifest.BuildDetails.Id,
-								},
-							},
-						},
-						"SQS_SUFFIX":   "-" + manifest.Name,
-						"CACHE_PREFIX": manifest.Name,
-						"CF_DOMAIN": map[string]any{
-							"Fn::GetAtt": []string{"CFDistribution", "DomainName"},
-						},
-						"APP_CONFIG_CACHE": "/tmp/storage/bootstrap/cache/config.php",
-						"APP_EVENTS_CACHE": "/tmp/storage/bootstrap/cache/events.php",
-						"APP_ROUTES_CACHE": "/tmp/storage/bootstrap/cache/routes-v7.php",
-					},
+					"Variables": environmentVariables(manifest),
 				},
 				"PackageType": "Image",
 				"Code": map[string]any{
 					"ImageUri": imageUri,
 				},
 				"VpcConfig": map[string]any{
-					"SecurityGroupIds": manifest.VPC.SecurityGroups,
-					"SubnetIds":        manifest.VPC.Subnets,
+					"SecurityGroupIds": vpcSecurityGroupIds(manifest),
+					"SubnetIds":        vpcSubnetIds(manifest),
 				},
 			},
 		},
@@ -278,6 +586,84 @@ func lambdaFunction(resourceName string, functionName string, imageUri string, m
 	return result
 }
 
+func environmentVariables(manifest *manifest.Manifest) map[string]any {
+	variables := map[string]any{
+		"SQS_PREFIX": map[string]any{
+			"Fn::Join": []any{
+				"",
+				[]any{
+					"https://",
+					"sqs.",
+					map[string]any{
+						"Ref": "AWS::Region",
+					},
+					".",
+					map[string]any{
+						"Ref": "AWS::URLSuffix",
+					},
+					"/",
+					map[string]any{
+						"Ref": "AWS::AccountId",
+					},
+				},
+			},
+		},
+		"ASSET_URL": map[string]any{
+			"Fn::Join": []any{"/",
+				[]any{
+					"assets",
+					manifest.BuildDetails.Id,
+				},
+			},
+		},
+		"SQS_SUFFIX":   "-" + manifest.Name,
+		"CACHE_PREFIX": manifest.Name,
+		"CF_DOMAIN": map[string]any{
+			"Fn::GetAtt": []string{"CFDistribution", "DomainName"},
+		},
+		"APP_CONFIG_CACHE": "/tmp/storage/bootstrap/cache/config.php",
+		"APP_EVENTS_CACHE": "/tmp/storage/bootstrap/cache/events.php",
+		"APP_ROUTES_CACHE": "/tmp/storage/bootstrap/cache/routes-v7.php",
+	}
+
+	if manifest.UsesDynamoDbCache() {
+		variables["DYNAMODB_CACHE_TABLE"] = map[string]any{
+			"Ref": "CacheTable",
+		}
+	}
+
+	if manifest.Storage != nil {
+		variables["AWS_BUCKET"] = map[string]any{
+			"Ref": "StorageBucket",
+		}
+	}
+
+	if manifest.Database != nil {
+		maps.Copy(variables, databaseVariables(manifest))
+	}
+
+	if manifest.Redis != nil {
+		maps.Copy(variables, redisVariables())
+	}
+
+	for queueName, deadLetterQueue := range getDeadLetterQueues(manifest) {
+		variables[deadLetterQueueVariableName(queueName)] = map[string]any{
+			"Ref": deadLetterQueue.resourceName,
+		}
+	}
+
+	if manifest.Websocket != nil {
+		variables["WEBSOCKET_ENDPOINT"] = map[string]any{
+			"Fn::Sub": "https://${WebsocketApi}.execute-api.${AWS::Region}.${AWS::URLSuffix}/" + websocketStageName,
+		}
+		variables["WEBSOCKET_CONNECTIONS_TABLE"] = map[string]any{
+			"Ref": "WebsocketConnectionsTable",
+		}
+	}
+
+	return variables
+}
+
 func lambdaAlias(httpLambdaResourceName string, resourceName string) map[string]any {
 	return map[string]any{
 		resourceName: map[string]any{
@@ -294,10 +680,16 @@ func lambdaAlias(httpLambdaResourceName string, resourceName string) map[string]
 }
 
 func warmer(httpLambdaResourceName string, httpLambdaAliasResourceName string, manifest *manifest.Manifest) map[string]any {
-	warm := "1"
+	warm := strconv.Itoa(manifest.WarmContainers())
+
+	// EventBridge only accepts the singular unit for a rate of 1.
+	scheduleExpression := fmt.Sprintf("rate(%d minutes)", manifest.WarmRate())
+	if manifest.WarmRate() == 1 {
+		scheduleExpression = "rate(1 minute)"
+	}
 
-	if manifest.HTTP.Warm != 0 {
-		warm = strconv.Itoa(manifest.HTTP.Warm)
+	if manifest.HTTP.WarmWindow.Enabled() {
+		scheduleExpression = manifest.HTTP.WarmWindow.ScheduleExpression(manifest.WarmRate())
 	}
 
 	return map[string]any{
@@ -308,7 +700,7 @@ func warmer(httpLambdaResourceName string, httpLambdaAliasResourceName string, m
 			},
 			"Properties": map[string]any{
 				"Name":               manifest.Name + "-warmer",
-				"ScheduleExpression": "rate(5 minutes)",
+				"ScheduleExpression": scheduleExpression,
 				"State":              "ENABLED",
 				"Targets": []any{
 					map[string]any{
@@ -419,50 +811,117 @@ func scheduler(cliLambdaResourceName string, manifest *manifest.Manifest) map[st
 	}
 }
 
-func queue(queueLambdaResourceName string, resourceName string, queueName string, manifest *manifest.Manifest, visibilityTimeout int) map[string]any {
+func queue(queueLambdaResourceName string, resourceName string, sqsQueue manifest.SqsQueue, stage *manifest.Manifest, visibilityTimeout int, queueConfiguration manifest.Queue) map[string]any {
 	if visibilityTimeout == 0 {
 		visibilityTimeout = 3
 	}
 
-	return map[string]any{
-		resourceName: map[string]any{
-			"Type": "AWS::SQS::Queue",
-			"Properties": map[string]any{
-				"QueueName":         queueName + "-" + manifest.Name,
-				"VisibilityTimeout": visibilityTimeout,
+	properties := map[string]any{
+		"QueueName":         GetQueueName(stage, sqsQueue.Name),
+		"VisibilityTimeout": visibilityTimeout,
+	}
+
+	if sqsQueue.Retention != 0 {
+		properties["MessageRetentionPeriod"] = sqsQueue.Retention
+	}
+
+	if sqsQueue.Delay != 0 {
+		properties["DelaySeconds"] = sqsQueue.Delay
+	}
+
+	if sqsQueue.ReceiveWait != 0 {
+		properties["ReceiveMessageWaitTimeSeconds"] = sqsQueue.ReceiveWait
+	}
+
+	if sqsQueue.MaxSize != 0 {
+		properties["MaximumMessageSize"] = sqsQueue.MaxSize
+	}
+
+	switch sqsQueue.Encryption {
+	case manifest.EncryptionSqs:
+		properties["SqsManagedSseEnabled"] = true
+	case manifest.EncryptionKms:
+		properties["KmsMasterKeyId"] = "alias/aws/sqs"
+
+		if sqsQueue.KmsKey != "" {
+			properties["KmsMasterKeyId"] = sqsQueue.KmsKey
+		}
+	case manifest.EncryptionNone:
+		properties["SqsManagedSseEnabled"] = false
+	}
+
+	if queueConfiguration.Fifo {
+		properties["FifoQueue"] = true
+		properties["ContentBasedDeduplication"] = queueConfiguration.UsesContentBasedDeduplication()
+
+		if queueConfiguration.Throughput == manifest.ThroughputHigh {
+			properties["DeduplicationScope"] = "messageGroup"
+			properties["FifoThroughputLimit"] = "perMessageGroupId"
+		}
+	}
+
+	if deadLetterQueue, ok := getDeadLetterQueues(stage)[sqsQueue.Name]; ok {
+		properties["RedrivePolicy"] = map[string]any{
+			"deadLetterTargetArn": map[string]any{
+				"Fn::GetAtt": []any{deadLetterQueue.resourceName, "Arn"},
 			},
+			"maxReceiveCount": queueConfiguration.Tries,
+		}
+	}
+
+	batchSize := queueConfiguration.BatchSize
+	if batchSize == 0 {
+		batchSize = 1
+	}
+
+	mappingProperties := map[string]any{
+		"BatchSize": batchSize,
+		"FunctionResponseTypes": []any{
+			"ReportBatchItemFailures",
 		},
-		resourceName + "QueueSourceMapping": map[string]any{
-			"Type": "AWS::Lambda::EventSourceMapping",
-			"Properties": map[string]any{
-				"BatchSize": 1,
-				"FunctionResponseTypes": []any{
-					"ReportBatchItemFailures",
-				},
-				"EventSourceArn": map[string]any{
-					"Fn::GetAtt": []any{
-						resourceName,
-						"Arn",
-					},
-				},
-				"FunctionName": map[string]any{
-					"Fn::Join": []any{
-						":",
-						[]any{
-							map[string]any{
-								"Ref": queueLambdaResourceName,
-							},
-							"live",
-						},
+		"EventSourceArn": map[string]any{
+			"Fn::GetAtt": []any{
+				resourceName,
+				"Arn",
+			},
+		},
+		"FunctionName": map[string]any{
+			"Fn::Join": []any{
+				":",
+				[]any{
+					map[string]any{
+						"Ref": queueLambdaResourceName,
 					},
+					"live",
 				},
 			},
 		},
 	}
+
+	if queueConfiguration.BatchingWindow > 0 {
+		mappingProperties["MaximumBatchingWindowInSeconds"] = queueConfiguration.BatchingWindow
+	}
+
+	if queueConfiguration.MaximumConcurrency > 0 {
+		mappingProperties["ScalingConfig"] = map[string]any{
+			"MaximumConcurrency": queueConfiguration.MaximumConcurrency,
+		}
+	}
+
+	return map[string]any{
+		resourceName: map[string]any{
+			"Type":       "AWS::SQS::Queue",
+			"Properties": properties,
+		},
+		resourceName + "QueueSourceMapping": map[string]any{
+			"Type":       "AWS::Lambda::EventSourceMapping",
+			"Properties": mappingProperties,
+		},
+	}
 }
 
 func apiGateway(httpLambdaResourceName string, httpLambdaAliasResourceName string, manifest *manifest.Manifest) map[string]any {
-	return map[string]any{
+	result := map[string]any{
 		"ApiGateway": map[string]any{
 			"Type": "AWS::ApiGatewayV2::Api",
 			"Properties": map[string]any{
@@ -584,74 +1043,324 @@ func apiGateway(httpLambdaResourceName string, httpLambdaAliasResourceName strin
 			},
 		},
 	}
-}
 
-func cloudFrontDistribution(apiGatewayResourceName string, manifest *manifest.Manifest) map[string]any {
-	output := map[string]any{
-		"CFDistribution": map[string]any{
-			"Type": "AWS::CloudFront::Distribution",
+	routeNames := []any{"ApiGatewayRoute"}
+	routeSettings := map[string]any{}
+
+	for _, route := range manifest.HTTP.Routes {
+		routeResourceName := "ApiGatewayRoute" + logicalId(route.Route)
+		routeNames = append(routeNames, routeResourceName)
+
+		result[routeResourceName] = map[string]any{
+			"Type": "AWS::ApiGatewayV2::Route",
 			"DependsOn": []any{
-				apiGatewayResourceName,
+				"ApiGatewayLambdaIntegration",
 			},
 			"Properties": map[string]any{
-				"DistributionConfig": map[string]any{
-					"HttpVersion": "http2",
-					"Origins": []any{
-						map[string]any{
-							"Id":         "assets-bucket",
-							"DomainName": fmt.Sprintf("%s-assets.s3.%s.amazonaws.com", manifest.Name, manifest.Region),
-							"S3OriginConfig": map[string]any{
-								"OriginAccessIdentity": "",
+				"ApiId": map[string]any{
+					"Ref": "ApiGateway",
+				},
+				"RouteKey":          route.Route,
+				"AuthorizationType": "NONE",
+				"Target": map[string]any{
+					"Fn::Join": []any{
+						"/",
+						[]any{
+							"integrations",
+							map[string]any{
+								"Ref": "ApiGatewayLambdaIntegration",
 							},
 						},
-						map[string]any{
-							"Id": "gateway",
-							"DomainName": map[string]any{
-								"Fn::Select": []any{"1", map[string]any{
-									"Fn::Split": []any{"//", map[string]any{
-										"Fn::GetAtt": []any{
-											apiGatewayResourceName,
-											"ApiEndpoint",
-										},
-									}},
-								}},
-							},
-							"CustomOriginConfig": map[string]any{
-								"OriginProtocolPolicy": "https-only",
-								"OriginSSLProtocols":   []string{"TLSv1.2"},
+					},
+				},
+			},
+		}
+
+		if route.Throttle.Enabled() {
+			routeSettings[route.Route] = throttlingSettings(route.Throttle)
+		}
+	}
+
+	result["ApiGatewayDeployment"].(map[string]any)["DependsOn"] = routeNames
+
+	stage := result["ApiGatewayStage"].(map[string]any)
+	stageProperties := stage["Properties"].(map[string]any)
+
+	// Route settings can only reference routes that already exist.
+	stage["DependsOn"] = routeNames
+
+	if manifest.HTTP.Throttle.Enabled() {
+		stageProperties["DefaultRouteSettings"] = throttlingSettings(manifest.HTTP.Throttle)
+	}
+
+	if len(routeSettings) > 0 {
+		stageProperties["RouteSettings"] = routeSettings
+	}
+
+	if manifest.HTTP.AccessLog.Enabled {
+		format := manifest.HTTP.AccessLog.Format
+		if format == "" {
+			format = defaultAccessLogFormat
+		}
+
+		result["ApiGatewayAccessLogGroup"] = map[string]any{
+			"Type": "AWS::Logs::LogGroup",
+			"Properties": map[string]any{
+				"LogGroupName":    "/aws/apigateway/" + manifest.Name + "-api",
+				"RetentionInDays": 14,
+			},
+		}
+
+		stageProperties["AccessLogSettings"] = map[string]any{
+			"DestinationArn": map[string]any{
+				"Fn::GetAtt": []any{"ApiGatewayAccessLogGroup", "Arn"},
+			},
+			"Format": format,
+		}
+	}
+
+	return result
+}
+
+func throttlingSettings(throttle manifest.Throttle) map[string]any {
+	settings := map[string]any{}
+
+	if throttle.Burst != 0 {
+		settings["ThrottlingBurstLimit"] = throttle.Burst
+	}
+
+	if throttle.Rate != 0 {
+		settings["ThrottlingRateLimit"] = throttle.Rate
+	}
+
+	return settings
+}
+
+func functionUrl(resourceName string, httpLambdaResourceName string, httpLambdaAliasResourceName string) map[string]any {
+	return map[string]any{
+		resourceName: map[string]any{
+			"Type": "AWS::Lambda::Url",
+			"DependsOn": []any{
+				httpLambdaAliasResourceName,
+			},
+			"Properties": map[string]any{
+				"AuthType": "NONE",
+				"TargetFunctionArn": map[string]any{
+					"Fn::GetAtt": []any{
+						httpLambdaResourceName,
+						"Arn",
+					},
+				},
+				"Qualifier": "live",
+			},
+		},
+		resourceName + "InvokePermission": map[string]any{
+			"Type": "AWS::Lambda::Permission",
+			"DependsOn": []any{
+				httpLambdaAliasResourceName,
+			},
+			"Properties": map[string]any{
+				"Action": "lambda:InvokeFunctionUrl",
+				"FunctionName": map[string]any{
+					"Fn::Join": []any{
+						":",
+						[]any{
+							map[string]any{
+								"Ref": httpLambdaResourceName,
 							},
+							"live",
 						},
 					},
-					"Enabled": "true",
-					"Comment": manifest.Name,
-					"DefaultCacheBehavior": map[string]any{
-						"AllowedMethods":       []any{"GET", "HEAD", "OPTIONS", "PUT", "PATCH", "POST", "DELETE"},
-						"TargetOriginId":       "gateway",
-						"CachePolicyId":        "b2884449-e4de-46a7-ac36-70bc7f1ddd6d",
-						"ViewerProtocolPolicy": "redirect-to-https",
+				},
+				"FunctionUrlAuthType": "NONE",
+				"Principal":           "*",
+			},
+		},
+	}
+}
+
+func cloudFrontDistribution(manifest *manifest.Manifest, webAclArn string) map[string]any {
+	output := map[string]any{}
+	redirects := manifest.HTTP.Domains.Redirects()
+
+	if len(redirects) > 0 {
+		maps.Copy(output, redirectFunction("RedirectFunction", redirects, manifest))
+	}
+
+	for _, group := range getDistributionGroups(manifest) {
+		distribution := distribution(manifest, webAclArn)
+		distributionConfig := distribution["Properties"].(map[string]any)["DistributionConfig"].(map[string]any)
+
+		if len(group.domains) > 0 {
+			distributionConfig["Aliases"] = group.domains
+			distributionConfig["ViewerCertificate"] = map[string]any{
+				"AcmCertificateArn": group.certificate,
+				"SslSupportMethod":  "sni-only",
+			}
+		}
+
+		if len(redirects) > 0 {
+			distributionConfig["DefaultCacheBehavior"].(map[string]any)["FunctionAssociations"] = []any{
+				map[string]any{
+					"EventType": "viewer-request",
+					"FunctionARN": map[string]any{
+						"Fn::GetAtt": []any{"RedirectFunction", "FunctionARN"},
 					},
-					"CacheBehaviors": []any{
-						map[string]any{
-							"AllowedMethods":        []any{"GET", "HEAD", "OPTIONS"},
-							"TargetOriginId":        "assets-bucket",
-							"PathPattern":           "/assets/*",
-							"CachePolicyId":         "658327ea-f89d-4fab-a63d-7e88639e58f6",
-							"OriginRequestPolicyId": "88a5eaf4-2fd4-4709-b370-b4c650ea3fcf",
-							"ViewerProtocolPolicy":  "redirect-to-https",
+				},
+			}
+		}
+
+		httpFunctionDistributionConfig(distributionConfig, manifest)
+
+		output[group.resourceName] = distribution
+	}
+
+	return output
+}
+
+func distribution(manifest *manifest.Manifest, webAclArn string) map[string]any {
+	gatewayOrigin := map[string]any{
+		"Id": "gateway",
+		"DomainName": map[string]any{
+			"Fn::Select": []any{"1", map[string]any{
+				"Fn::Split": []any{"//", map[string]any{
+					"Fn::GetAtt": []any{"ApiGateway", "ApiEndpoint"},
+				}},
+			}},
+		},
+		"CustomOriginConfig": map[string]any{
+			"OriginProtocolPolicy": "https-only",
+			"OriginSSLProtocols":   []string{"TLSv1.2"},
+		},
+	}
+
+	gatewayResourceName := "ApiGateway"
+
+	if manifest.UsesFunctionUrl() {
+		gatewayResourceName = "FunctionUrl"
+		gatewayOrigin = functionUrlOrigin("gateway", "FunctionUrl", manifest.HTTP.Timeout)
+	}
+
+	output := map[string]any{
+		"Type": "AWS::CloudFront::Distribution",
+		"DependsOn": []any{
+			gatewayResourceName,
+		},
+		"Properties": map[string]any{
+			"DistributionConfig": map[string]any{
+				"HttpVersion": "http2",
+				"Origins": []any{
+					map[string]any{
+						"Id":         "assets-bucket",
+						"DomainName": fmt.Sprintf("%s-assets.s3.%s.amazonaws.com", manifest.Name, manifest.Region),
+						"S3OriginConfig": map[string]any{
+							"OriginAccessIdentity": "",
 						},
 					},
+					gatewayOrigin,
+				},
+				"Enabled": "true",
+				"Comment": manifest.Name,
+				"DefaultCacheBehavior": map[string]any{
+					"AllowedMethods":       []any{"GET", "HEAD", "OPTIONS", "PUT", "PATCH", "POST", "DELETE"},
+					"TargetOriginId":       "gateway",
+					"CachePolicyId":        "b2884449-e4de-46a7-ac36-70bc7f1ddd6d",
+					"ViewerProtocolPolicy": "redirect-to-https",
+				},
+				"CacheBehaviors": []any{
+					map[string]any{
+						"AllowedMethods":        []any{"GET", "HEAD", "OPTIONS"},
+						"TargetOriginId":        "assets-bucket",
+						"PathPattern":           "/assets/*",
+						"CachePolicyId":         "658327ea-f89d-4fab-a63d-7e88639e58f6",
+						"OriginRequestPolicyId": "88a5eaf4-2fd4-4709-b370-b4c650ea3fcf",
+						"ViewerProtocolPolicy":  "redirect-to-https",
+					},
 				},
 			},
 		},
 	}
 
-	if len(manifest.HTTP.Domains) > 0 {
-		output["CFDistribution"].(map[string]any)["Properties"].(map[string]any)["DistributionConfig"].(map[string]any)["Aliases"] = strings.Split(strings.ReplaceAll(manifest.HTTP.Domains, " ", ""), ",")
-		output["CFDistribution"].(map[string]any)["Properties"].(map[string]any)["DistributionConfig"].(map[string]any)["ViewerCertificate"] = map[string]any{"" +
-			"AcmCertificateArn": "arn:aws:acm:us-east-1:324027754711:certificate/e3109ab1-3ca4-4f33-b580-620bfdaf7617",
-			"SslSupportMethod": "sni-only",
+	distributionConfig := output["Properties"].(map[string]any)["DistributionConfig"].(map[string]any)
+
+	var errorResponses []any
+
+	if manifest.HTTP.ErrorPage != "" {
+		for _, errorCode := range []int{500, 502, 503, 504} {
+			errorResponses = append(errorResponses, map[string]any{
+				"ErrorCode":          errorCode,
+				"ResponseCode":       errorCode,
+				"ResponsePagePath":   getAssetPath(manifest, manifest.HTTP.ErrorPage),
+				"ErrorCachingMinTTL": 0,
+			})
+		}
+	}
+
+	if manifest.HTTP.MaintenancePage != "" {
+		// S3 rejects requests in maintenance mode, so CloudFront responds with the maintenance page.
+		distributionConfig["DefaultCacheBehavior"].(map[string]any)["TargetOriginId"] = map[string]any{
+			"Fn::If": []any{"MaintenanceModeEnabled", "assets-bucket", "gateway"},
+		}
+
+		for _, errorCode := range []int{403, 404, 405} {
+			errorResponses = append(errorResponses, map[string]any{
+				"Fn::If": []any{
+					"MaintenanceModeEnabled",
+					map[string]any{
+						"ErrorCode":          errorCode,
+						"ResponseCode":       503,
+						"ResponsePagePath":   getAssetPath(manifest, manifest.HTTP.MaintenancePage),
+						"ErrorCachingMinTTL": 0,
+					},
+					map[string]any{"Ref": "AWS::NoValue"},
+				},
+			})
 		}
 	}
 
+	if len(errorResponses) > 0 {
+		distributionConfig["CustomErrorResponses"] = errorResponses
+	}
+
+	if webAclArn != "" {
+		distributionConfig["WebACLId"] = webAclArn
+	}
+
+	if manifest.HTTP.HostedZone != "" {
+		distributionConfig["IPV6Enabled"] = true
+	}
+
 	return output
 }
+
+func functionUrlOrigin(id string, functionUrlResourceName string, timeout int) map[string]any {
+	origin := map[string]any{
+		"Id": id,
+		"DomainName": map[string]any{
+			"Fn::Select": []any{"2", map[string]any{
+				"Fn::Split": []any{"/", map[string]any{
+					"Fn::GetAtt": []any{functionUrlResourceName, "FunctionUrl"},
+				}},
+			}},
+		},
+		"CustomOriginConfig": map[string]any{
+			"OriginProtocolPolicy": "https-only",
+			"OriginSSLProtocols":   []string{"TLSv1.2"},
+		},
+	}
+
+	// Function URLs aren't limited to the 30-second timeout of API Gateway.
+	if timeout > 30 {
+		if timeout > 60 {
+			timeout = 60
+		}
+
+		origin["CustomOriginConfig"].(map[string]any)["OriginReadTimeout"] = timeout
+	}
+
+	return origin
+}
+
+func getAssetPath(manifest *manifest.Manifest, path string) string {
+	return "/assets/" + manifest.BuildDetails.Id + "/" + strings.TrimPrefix(path, "/")
+}
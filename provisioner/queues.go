@@ -0,0 +1,64 @@
+package provisioner
+
+import (
+	"fmt"
+	"golang.org/x/exp/slices"
+	"hover/aws"
+	"hover/utils/manifest"
+	"sort"
+)
+
+func GetQueueNames(manifest *manifest.Manifest) []string {
+	var names []string
+
+	for _, queueConfiguration := range manifest.Queue {
+		names = append(names, queueConfiguration.QueueNames()...)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// The stage name is appended the same way SQS_SUFFIX makes Laravel do it.
+func GetQueueName(manifest *manifest.Manifest, queueName string) string {
+	for _, queueConfiguration := range manifest.Queue {
+		if slices.Contains(queueConfiguration.QueueNames(), queueName) {
+			return getSqsQueueName(manifest, queueName, queueConfiguration.Fifo)
+		}
+	}
+
+	return getSqsQueueName(manifest, queueName, false)
+}
+
+func getSqsQueueName(manifest *manifest.Manifest, name string, fifo bool) string {
+	if fifo {
+		return name + "-" + manifest.Name + ".fifo"
+	}
+
+	return name + "-" + manifest.Name
+}
+
+func GetQueueUrl(manifest *manifest.Manifest, queueName string, deadLetter bool, aws *aws.Aws) (*string, error) {
+	if !slices.Contains(GetQueueNames(manifest), queueName) {
+		return nil, fmt.Errorf("the `%s` queue isn't defined in the manifest of stage `%s`", queueName, manifest.Name)
+	}
+
+	name := GetQueueName(manifest, queueName)
+
+	if deadLetter {
+		deadLetterQueueName, ok := GetDeadLetterQueueName(manifest, queueName)
+		if !ok {
+			return nil, fmt.Errorf("the `%s` queue has no dead-letter queue", queueName)
+		}
+
+		name = deadLetterQueueName
+	}
+
+	url, err := aws.GetQueueUrl(&name)
+	if err != nil {
+		return nil, fmt.Errorf("unable to find the `%s` queue. Make sure the stage is deployed. Error: %w", name, err)
+	}
+
+	return url, nil
+}
@@ -0,0 +1,77 @@
+package provisioner
+
+import (
+	"hover/utils/manifest"
+	"strconv"
+)
+
+const redisPort = 6379
+
+func redis(manifest *manifest.Manifest) map[string]any {
+	version := manifest.Redis.Version
+	if version == "" {
+		version = "7"
+	}
+
+	properties := map[string]any{
+		"ServerlessCacheName": manifest.Name,
+		"Engine":              "redis",
+		"MajorEngineVersion":  version,
+		"SubnetIds":           vpcSubnetIds(manifest),
+		"SecurityGroupIds":    vpcSecurityGroupIds(manifest),
+	}
+
+	usageLimits := map[string]any{}
+
+	if manifest.Redis.MaxStorage != 0 {
+		usageLimits["DataStorage"] = map[string]any{
+			"Maximum": manifest.Redis.MaxStorage,
+			"Unit":    "GB",
+		}
+	}
+
+	if manifest.Redis.MaxEcpu != 0 {
+		usageLimits["ECPUPerSecond"] = map[string]any{
+			"Maximum": manifest.Redis.MaxEcpu,
+		}
+	}
+
+	if len(usageLimits) > 0 {
+		properties["CacheUsageLimits"] = usageLimits
+	}
+
+	result := map[string]any{
+		"RedisCache": map[string]any{
+			"Type":       "AWS::ElastiCache::ServerlessCache",
+			"Properties": properties,
+		},
+	}
+
+	for i, securityGroup := range vpcSecurityGroupIds(manifest) {
+		result["RedisIngress"+strconv.Itoa(i+1)] = map[string]any{
+			"Type": "AWS::EC2::SecurityGroupIngress",
+			"Properties": map[string]any{
+				"Description":           "Redis access from the " + manifest.Name + " functions",
+				"GroupId":               securityGroup,
+				"SourceSecurityGroupId": securityGroup,
+				"IpProtocol":            "tcp",
+				"FromPort":              redisPort,
+				"ToPort":                redisPort,
+			},
+		}
+	}
+
+	return result
+}
+
+func redisVariables() map[string]any {
+	return map[string]any{
+		"REDIS_HOST": map[string]any{
+			"Fn::GetAtt": []any{"RedisCache", "Endpoint.Address"},
+		},
+		"REDIS_PORT": map[string]any{
+			"Fn::GetAtt": []any{"RedisCache", "Endpoint.Port"},
+		},
+		"REDIS_SCHEME": "tls",
+	}
+}
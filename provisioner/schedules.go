@@ -0,0 +1,96 @@
+package provisioner
+
+import (
+	"encoding/json"
+	"hover/utils/manifest"
+	"strconv"
+)
+
+func schedules(cliLambdaResourceName string, manifest *manifest.Manifest) map[string]any {
+	if len(manifest.Schedules) == 0 {
+		return map[string]any{}
+	}
+
+	result := map[string]any{
+		"SchedulesRole": map[string]any{
+			"Type": "AWS::IAM::Role",
+			"Properties": map[string]any{
+				"AssumeRolePolicyDocument": map[string]any{
+					"Version": "2012-10-17",
+					"Statement": []any{
+						map[string]any{
+							"Effect": "Allow",
+							"Principal": map[string]any{
+								"Service": "scheduler.amazonaws.com",
+							},
+							"Action": "sts:AssumeRole",
+						},
+					},
+				},
+				"Policies": []any{
+					map[string]any{
+						"PolicyName": "invoke-cli-function",
+						"PolicyDocument": map[string]any{
+							"Version": "2012-10-17",
+							"Statement": []any{
+								map[string]any{
+									"Effect": "Allow",
+									"Action": "lambda:InvokeFunction",
+									"Resource": map[string]any{
+										"Fn::Sub": "${" + cliLambdaResourceName + ".Arn}:live",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for i, schedule := range manifest.Schedules {
+		input := map[string]any{
+			"command": schedule.Command,
+		}
+
+		if schedule.Input != nil {
+			input["payload"] = schedule.Input
+		}
+
+		inputJson, _ := json.Marshal(input)
+
+		state := "ENABLED"
+		if !schedule.IsEnabled() {
+			state = "DISABLED"
+		}
+
+		properties := map[string]any{
+			"Description":        manifest.Name + ": " + schedule.Command,
+			"ScheduleExpression": schedule.Expression,
+			"State":              state,
+			"FlexibleTimeWindow": map[string]any{
+				"Mode": "OFF",
+			},
+			"Target": map[string]any{
+				"Arn": map[string]any{
+					"Fn::Sub": "${" + cliLambdaResourceName + ".Arn}:live",
+				},
+				"RoleArn": map[string]any{
+					"Fn::GetAtt": []any{"SchedulesRole", "Arn"},
+				},
+				"Input": string(inputJson),
+			},
+		}
+
+		if schedule.Timezone != "" {
+			properties["ScheduleExpressionTimezone"] = schedule.Timezone
+		}
+
+		result["Schedule"+strconv.Itoa(i+1)] = map[string]any{
+			"Type":       "AWS::Scheduler::Schedule",
+			"Properties": properties,
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,90 @@
+package provisioner
+
+import (
+	"hover/utils/manifest"
+	"strconv"
+)
+
+func storageBucket(manifest *manifest.Manifest) map[string]any {
+	noncurrentVersionExpiration := manifest.Storage.NoncurrentVersionExpiration
+	if noncurrentVersionExpiration == 0 {
+		noncurrentVersionExpiration = 30
+	}
+
+	rules := []any{
+		map[string]any{
+			"Id":     "expire-noncurrent-versions",
+			"Status": "Enabled",
+			"NoncurrentVersionExpiration": map[string]any{
+				"NoncurrentDays": noncurrentVersionExpiration,
+			},
+			"AbortIncompleteMultipartUpload": map[string]any{
+				"DaysAfterInitiation": 7,
+			},
+		},
+	}
+
+	for i, expiration := range manifest.Storage.Expirations {
+		rules = append(rules, map[string]any{
+			"Id":               "expiration-" + strconv.Itoa(i+1),
+			"Status":           "Enabled",
+			"Prefix":           expiration.Prefix,
+			"ExpirationInDays": expiration.Days,
+		})
+	}
+
+	properties := map[string]any{
+		"PublicAccessBlockConfiguration": map[string]any{
+			"BlockPublicAcls":       true,
+			"BlockPublicPolicy":     true,
+			"IgnorePublicAcls":      true,
+			"RestrictPublicBuckets": true,
+		},
+		"BucketEncryption": map[string]any{
+			"ServerSideEncryptionConfiguration": []any{
+				map[string]any{
+					"ServerSideEncryptionByDefault": map[string]any{
+						"SSEAlgorithm": "AES256",
+					},
+				},
+			},
+		},
+		"VersioningConfiguration": map[string]any{
+			"Status": "Enabled",
+		},
+		"LifecycleConfiguration": map[string]any{
+			"Rules": rules,
+		},
+	}
+
+	corsOrigins := manifest.Storage.CorsOrigins
+
+	if len(corsOrigins) == 0 {
+		for _, domain := range manifest.DomainNames() {
+			corsOrigins = append(corsOrigins, "https://"+domain)
+		}
+	}
+
+	if len(corsOrigins) > 0 {
+		properties["CorsConfiguration"] = map[string]any{
+			"CorsRules": []any{
+				map[string]any{
+					"AllowedOrigins": corsOrigins,
+					"AllowedMethods": []string{"GET", "PUT", "POST", "HEAD"},
+					"AllowedHeaders": []string{"*"},
+					"ExposedHeaders": []string{"ETag"},
+					"MaxAge":         3600,
+				},
+			},
+		}
+	}
+
+	return map[string]any{
+		"StorageBucket": map[string]any{
+			"Type":                "AWS::S3::Bucket",
+			"DeletionPolicy":      "Retain",
+			"UpdateReplacePolicy": "Retain",
+			"Properties":          properties,
+		},
+	}
+}
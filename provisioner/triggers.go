@@ -0,0 +1,264 @@
+package provisioner
+
+import (
+	"encoding/json"
+	"fmt"
+	cloudformationTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	s3Types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go/ptr"
+	"golang.org/x/exp/slices"
+	"hover/aws"
+	"hover/utils"
+	"hover/utils/manifest"
+	"strconv"
+	"strings"
+)
+
+// CloudFormation can't add notifications to existing buckets, see ConfigureS3Triggers.
+func triggers(cliLambdaResourceName string, manifest *manifest.Manifest) map[string]any {
+	result := map[string]any{}
+
+	functionArn := map[string]any{
+		"Fn::Sub": "${" + cliLambdaResourceName + ".Arn}:live",
+	}
+
+	permission := func(principal string, sourceArn any) map[string]any {
+		return map[string]any{
+			"Type": "AWS::Lambda::Permission",
+			"DependsOn": []any{
+				"CliLambdaLiveAlias",
+			},
+			"Properties": map[string]any{
+				"FunctionName": functionArn,
+				"Action":       "lambda:InvokeFunction",
+				"Principal":    principal,
+				"SourceArn":    sourceArn,
+				"SourceAccount": map[string]any{
+					"Ref": "AWS::AccountId",
+				},
+			},
+		}
+	}
+
+	for i, trigger := range manifest.Triggers {
+		resourceName := "Trigger" + strconv.Itoa(i+1)
+
+		switch trigger.Type {
+		case "s3":
+			result[resourceName+"Permission"] = permission("s3.amazonaws.com", "arn:aws:s3:::"+trigger.Bucket)
+		case "sns":
+			result[resourceName+"Subscription"] = map[string]any{
+				"Type": "AWS::SNS::Subscription",
+				"DependsOn": []any{
+					resourceName + "Permission",
+				},
+				"Properties": map[string]any{
+					"Protocol": "lambda",
+					"TopicArn": trigger.Topic,
+					"Endpoint": functionArn,
+				},
+			}
+
+			result[resourceName+"Permission"] = permission("sns.amazonaws.com", trigger.Topic)
+		case "eventbridge":
+			commandJson, _ := json.Marshal(trigger.Command)
+
+			properties := map[string]any{
+				"EventPattern": trigger.Pattern,
+				"State":        "ENABLED",
+				"Targets": []any{
+					map[string]any{
+						"Arn": functionArn,
+						"Id":  "hover-trigger",
+						"InputTransformer": map[string]any{
+							"InputTemplate": `{"command": ` + string(commandJson) + `, "payload": <aws.events.event.json>}`,
+						},
+					},
+				},
+			}
+
+			if trigger.Bus != "" {
+				properties["EventBusName"] = trigger.Bus
+			}
+
+			result[resourceName+"Rule"] = map[string]any{
+				"Type":       "AWS::Events::Rule",
+				"Properties": properties,
+			}
+
+			result[resourceName+"Permission"] = permission("events.amazonaws.com", map[string]any{
+				"Fn::GetAtt": []any{resourceName + "Rule", "Arn"},
+			})
+		}
+	}
+
+	return result
+}
+
+func ConfigureS3Triggers(manifest *manifest.Manifest, previousStack *cloudformationTypes.Stack, aws *aws.Aws) error {
+	buckets := getS3TriggerBuckets(manifest)
+
+	var removedBuckets []string
+
+	for _, bucket := range getConfiguredS3TriggerBuckets(previousStack) {
+		if !slices.Contains(buckets, bucket) {
+			removedBuckets = append(removedBuckets, bucket)
+		}
+	}
+
+	if len(buckets) == 0 && len(removedBuckets) == 0 {
+		return nil
+	}
+
+	utils.PrintStep("Configuring S3 triggers")
+
+	for _, bucket := range removedBuckets {
+		// The bucket may have been deleted since.
+		err := updateBucketNotifications(bucket, nil, manifest, aws)
+		if err != nil {
+			utils.PrintWarning(err.Error())
+			continue
+		}
+
+		fmt.Printf("Removed the notifications of the %s bucket\n", bucket)
+	}
+
+	if len(buckets) == 0 {
+		return nil
+	}
+
+	functionName := GetLambdaFunctionName(manifest.Name, "cli")
+
+	function, err := aws.GetLambda(&functionName, nil)
+	if err != nil {
+		return fmt.Errorf("unable to read the CLI function. Error: %w", err)
+	}
+
+	functionArn := *function.Configuration.FunctionArn + ":live"
+
+	for _, bucket := range buckets {
+		var configurations []s3Types.LambdaFunctionConfiguration
+
+		for i, trigger := range manifest.Triggers {
+			if trigger.Type != "s3" || trigger.Bucket != bucket {
+				continue
+			}
+
+			events := []s3Types.Event{"s3:ObjectCreated:*"}
+
+			if len(trigger.Events) > 0 {
+				events = nil
+
+				for _, event := range trigger.Events {
+					events = append(events, s3Types.Event(event))
+				}
+			}
+
+			configuration := s3Types.LambdaFunctionConfiguration{
+				Id:                ptr.String(getS3TriggerIdPrefix(manifest) + strconv.Itoa(i+1)),
+				LambdaFunctionArn: ptr.String(functionArn),
+				Events:            events,
+			}
+
+			var filterRules []s3Types.FilterRule
+
+			if trigger.Prefix != "" {
+				filterRules = append(filterRules, s3Types.FilterRule{Name: s3Types.FilterRuleNamePrefix, Value: ptr.String(trigger.Prefix)})
+			}
+
+			if trigger.Suffix != "" {
+				filterRules = append(filterRules, s3Types.FilterRule{Name: s3Types.FilterRuleNameSuffix, Value: ptr.String(trigger.Suffix)})
+			}
+
+			if len(filterRules) > 0 {
+				configuration.Filter = &s3Types.NotificationConfigurationFilter{
+					Key: &s3Types.S3KeyFilter{FilterRules: filterRules},
+				}
+			}
+
+			configurations = append(configurations, configuration)
+		}
+
+		err := updateBucketNotifications(bucket, configurations, manifest, aws)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Configured the notifications of the %s bucket\n", bucket)
+	}
+
+	return nil
+}
+
+func DeleteS3Triggers(manifest *manifest.Manifest, stack *cloudformationTypes.Stack, aws *aws.Aws) error {
+	for _, bucket := range GetS3TriggerBuckets(manifest, stack) {
+		err := updateBucketNotifications(bucket, nil, manifest, aws)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func GetS3TriggerBuckets(manifest *manifest.Manifest, stack *cloudformationTypes.Stack) []string {
+	buckets := getS3TriggerBuckets(manifest)
+
+	for _, bucket := range getConfiguredS3TriggerBuckets(stack) {
+		if !slices.Contains(buckets, bucket) {
+			buckets = append(buckets, bucket)
+		}
+	}
+
+	return buckets
+}
+
+func updateBucketNotifications(bucket string, configurations []s3Types.LambdaFunctionConfiguration, manifest *manifest.Manifest, aws *aws.Aws) error {
+	current, err := aws.GetBucketNotifications(&bucket)
+	if err != nil {
+		return fmt.Errorf("unable to read the notifications of the %s bucket. Error: %w", bucket, err)
+	}
+
+	for _, configuration := range current.LambdaFunctionConfigurations {
+		if configuration.Id == nil || !strings.HasPrefix(*configuration.Id, getS3TriggerIdPrefix(manifest)) {
+			configurations = append(configurations, configuration)
+		}
+	}
+
+	err = aws.PutBucketNotifications(&bucket, &s3Types.NotificationConfiguration{
+		EventBridgeConfiguration:     current.EventBridgeConfiguration,
+		LambdaFunctionConfigurations: configurations,
+		QueueConfigurations:          current.QueueConfigurations,
+		TopicConfigurations:          current.TopicConfigurations,
+	})
+	if err != nil {
+		return fmt.Errorf("unable to update the notifications of the %s bucket. Error: %w", bucket, err)
+	}
+
+	return nil
+}
+
+func getS3TriggerBuckets(manifest *manifest.Manifest) []string {
+	var buckets []string
+
+	for _, trigger := range manifest.Triggers {
+		if trigger.Type == "s3" && !slices.Contains(buckets, trigger.Bucket) {
+			buckets = append(buckets, trigger.Bucket)
+		}
+	}
+
+	return buckets
+}
+
+func getConfiguredS3TriggerBuckets(stack *cloudformationTypes.Stack) []string {
+	value := GetStackOutput(stack, "S3TriggerBuckets")
+	if value == "" {
+		return nil
+	}
+
+	return strings.Split(value, ",")
+}
+
+func getS3TriggerIdPrefix(manifest *manifest.Manifest) string {
+	return "hover-" + manifest.Name + "-trigger-"
+}
@@ -0,0 +1,437 @@
+package provisioner
+
+import (
+	"fmt"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"hover/aws"
+	"hover/utils/manifest"
+	"strconv"
+	"strings"
+)
+
+var vpcSubnets = []struct {
+	resourceName string
+	cidrBlock    string
+	zone         int
+	public       bool
+}{
+	{"PublicSubnet1", "10.0.0.0/20", 0, true},
+	{"PublicSubnet2", "10.0.16.0/20", 1, true},
+	{"PrivateSubnet1", "10.0.128.0/20", 0, false},
+	{"PrivateSubnet2", "10.0.144.0/20", 1, false},
+}
+
+func vpc(manifest *manifest.Manifest) map[string]any {
+	result := map[string]any{
+		"Vpc": map[string]any{
+			"Type": "AWS::EC2::VPC",
+			"Properties": map[string]any{
+				"CidrBlock":          "10.0.0.0/16",
+				"EnableDnsSupport":   true,
+				"EnableDnsHostnames": true,
+				"Tags":               vpcTags(manifest, "vpc"),
+			},
+		},
+		"InternetGateway": map[string]any{
+			"Type": "AWS::EC2::InternetGateway",
+			"Properties": map[string]any{
+				"Tags": vpcTags(manifest, "igw"),
+			},
+		},
+		"InternetGatewayAttachment": map[string]any{
+			"Type": "AWS::EC2::VPCGatewayAttachment",
+			"Properties": map[string]any{
+				"VpcId": map[string]any{
+					"Ref": "Vpc",
+				},
+				"InternetGatewayId": map[string]any{
+					"Ref": "InternetGateway",
+				},
+			},
+		},
+		"PublicRouteTable": map[string]any{
+			"Type": "AWS::EC2::RouteTable",
+			"Properties": map[string]any{
+				"VpcId": map[string]any{
+					"Ref": "Vpc",
+				},
+				"Tags": vpcTags(manifest, "public"),
+			},
+		},
+		"PublicRoute": map[string]any{
+			"Type":      "AWS::EC2::Route",
+			"DependsOn": []any{"InternetGatewayAttachment"},
+			"Properties": map[string]any{
+				"RouteTableId": map[string]any{
+					"Ref": "PublicRouteTable",
+				},
+				"DestinationCidrBlock": "0.0.0.0/0",
+				"GatewayId": map[string]any{
+					"Ref": "InternetGateway",
+				},
+			},
+		},
+		"PrivateRouteTable": map[string]any{
+			"Type": "AWS::EC2::RouteTable",
+			"Properties": map[string]any{
+				"VpcId": map[string]any{
+					"Ref": "Vpc",
+				},
+				"Tags": vpcTags(manifest, "private"),
+			},
+		},
+		"FunctionsSecurityGroup": map[string]any{
+			"Type": "AWS::EC2::SecurityGroup",
+			"Properties": map[string]any{
+				"GroupDescription": "Functions of the " + manifest.Name + " stage",
+				"VpcId": map[string]any{
+					"Ref": "Vpc",
+				},
+				"Tags": vpcTags(manifest, "functions"),
+			},
+		},
+	}
+
+	for _, subnet := range vpcSubnets {
+		routeTable := "PrivateRouteTable"
+		name := "private-" + strconv.Itoa(subnet.zone+1)
+
+		if subnet.public {
+			routeTable = "PublicRouteTable"
+			name = "public-" + strconv.Itoa(subnet.zone+1)
+		}
+
+		result[subnet.resourceName] = map[string]any{
+			"Type": "AWS::EC2::Subnet",
+			"Properties": map[string]any{
+				"VpcId": map[string]any{
+					"Ref": "Vpc",
+				},
+				"CidrBlock": subnet.cidrBlock,
+				"AvailabilityZone": map[string]any{
+					"Fn::Select": []any{
+						subnet.zone,
+						map[string]any{
+							"Fn::GetAZs": "",
+						},
+					},
+				},
+				"MapPublicIpOnLaunch": subnet.public,
+				"Tags":                vpcTags(manifest, name),
+			},
+		}
+
+		result[subnet.resourceName+"RouteTableAssociation"] = map[string]any{
+			"Type": "AWS::EC2::SubnetRouteTableAssociation",
+			"Properties": map[string]any{
+				"SubnetId": map[string]any{
+					"Ref": subnet.resourceName,
+				},
+				"RouteTableId": map[string]any{
+					"Ref": routeTable,
+				},
+			},
+		}
+	}
+
+	if manifest.VPC.UsesNat() {
+		result["NatGatewayIp"] = map[string]any{
+			"Type":      "AWS::EC2::EIP",
+			"DependsOn": []any{"InternetGatewayAttachment"},
+			"Properties": map[string]any{
+				"Domain": "vpc",
+				"Tags":   vpcTags(manifest, "nat"),
+			},
+		}
+
+		result["NatGateway"] = map[string]any{
+			"Type": "AWS::EC2::NatGateway",
+			"Properties": map[string]any{
+				"AllocationId": map[string]any{
+					"Fn::GetAtt": []any{"NatGatewayIp", "AllocationId"},
+				},
+				"SubnetId": map[string]any{
+					"Ref": "PublicSubnet1",
+				},
+				"Tags": vpcTags(manifest, "nat"),
+			},
+		}
+
+		result["PrivateRoute"] = map[string]any{
+			"Type": "AWS::EC2::Route",
+			"Properties": map[string]any{
+				"RouteTableId": map[string]any{
+					"Ref": "PrivateRouteTable",
+				},
+				"DestinationCidrBlock": "0.0.0.0/0",
+				"NatGatewayId": map[string]any{
+					"Ref": "NatGateway",
+				},
+			},
+		}
+	}
+
+	maps.Copy(result, vpcEndpoints(manifest, map[string]any{"Ref": "Vpc"}, vpcSubnetIds(manifest), []any{map[string]any{"Ref": "PrivateRouteTable"}}))
+
+	return result
+}
+
+func vpcEndpoints(manifest *manifest.Manifest, vpcId any, subnetIds []any, routeTableIds []any) map[string]any {
+	result := map[string]any{}
+
+	var interfaceEndpoints []string
+
+	for _, endpoint := range manifest.VPC.Endpoints {
+		switch endpoint {
+		case "s3", "dynamodb":
+			result[logicalId(endpoint)+"Endpoint"] = map[string]any{
+				"Type": "AWS::EC2::VPCEndpoint",
+				"Properties": map[string]any{
+					"VpcEndpointType": "Gateway",
+					"ServiceName": map[string]any{
+						"Fn::Sub": "com.amazonaws.${AWS::Region}." + endpoint,
+					},
+					"VpcId":         vpcId,
+					"RouteTableIds": routeTableIds,
+				},
+			}
+		default:
+			interfaceEndpoints = append(interfaceEndpoints, endpoint)
+		}
+	}
+
+	if len(interfaceEndpoints) == 0 {
+		return result
+	}
+
+	var ingress []any
+
+	for _, securityGroup := range vpcSecurityGroupIds(manifest) {
+		ingress = append(ingress, map[string]any{
+			"IpProtocol":            "tcp",
+			"FromPort":              443,
+			"ToPort":                443,
+			"SourceSecurityGroupId": securityGroup,
+		})
+	}
+
+	result["EndpointsSecurityGroup"] = map[string]any{
+		"Type": "AWS::EC2::SecurityGroup",
+		"Properties": map[string]any{
+			"GroupDescription":     "VPC endpoints of the " + manifest.Name + " stage",
+			"VpcId":                vpcId,
+			"SecurityGroupIngress": ingress,
+			"Tags":                 vpcTags(manifest, "endpoints"),
+		},
+	}
+
+	for _, endpoint := range interfaceEndpoints {
+		result[logicalId(endpoint)+"Endpoint"] = map[string]any{
+			"Type": "AWS::EC2::VPCEndpoint",
+			"Properties": map[string]any{
+				"VpcEndpointType": "Interface",
+				"ServiceName": map[string]any{
+					"Fn::Sub": "com.amazonaws.${AWS::Region}." + endpoint,
+				},
+				"VpcId":             vpcId,
+				"SubnetIds":         subnetIds,
+				"SecurityGroupIds":  []any{map[string]any{"Ref": "EndpointsSecurityGroup"}},
+				"PrivateDnsEnabled": true,
+			},
+		}
+	}
+
+	return result
+}
+
+func vpcTags(manifest *manifest.Manifest, name string) []any {
+	return []any{
+		map[string]any{
+			"Key":   "Name",
+			"Value": manifest.Name + "-" + name,
+		},
+	}
+}
+
+func vpcSubnetIds(manifest *manifest.Manifest) []any {
+	result := []any{}
+
+	if manifest.VPC.Create {
+		for _, subnet := range vpcSubnets {
+			if !subnet.public {
+				result = append(result, map[string]any{"Ref": subnet.resourceName})
+			}
+		}
+
+		return result
+	}
+
+	for _, subnet := range manifest.VPC.Subnets {
+		result = append(result, subnet)
+	}
+
+	return result
+}
+
+func vpcSecurityGroupIds(manifest *manifest.Manifest) []any {
+	if manifest.VPC.Create {
+		return []any{map[string]any{"Ref": "FunctionsSecurityGroup"}}
+	}
+
+	result := []any{}
+
+	for _, securityGroup := range manifest.VPC.SecurityGroups {
+		result = append(result, securityGroup)
+	}
+
+	return result
+}
+
+type existingVpc struct {
+	id string
+	// An endpoint only supports one subnet per availability zone.
+	endpointSubnetIds []string
+	routeTableIds     []string
+	// Lambda can't reach the internet through an internet gateway.
+	hasNat bool
+}
+
+func getExistingVpc(manifest *manifest.Manifest, aws *aws.Aws) (*existingVpc, error) {
+	subnets, err := aws.GetSubnets(manifest.VPC.Subnets)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the subnets of the VPC. Error: %w", err)
+	}
+
+	result := &existingVpc{
+		hasNat: true,
+	}
+
+	var zones []string
+
+	for _, subnet := range subnets {
+		if result.id == "" {
+			result.id = *subnet.VpcId
+		}
+
+		if *subnet.VpcId != result.id {
+			return nil, fmt.Errorf("the subnets of the manifest belong to different VPCs")
+		}
+
+		if !slices.Contains(zones, *subnet.AvailabilityZone) {
+			zones = append(zones, *subnet.AvailabilityZone)
+			result.endpointSubnetIds = append(result.endpointSubnetIds, *subnet.SubnetId)
+		}
+	}
+
+	routeTables, err := aws.GetRouteTables(&result.id)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the route tables of the VPC. Error: %w", err)
+	}
+
+	for _, subnet := range subnets {
+		mainRouteTable, subnetRouteTable := -1, -1
+
+		for i, routeTable := range routeTables {
+			for _, association := range routeTable.Associations {
+				if association.Main != nil && *association.Main {
+					mainRouteTable = i
+				}
+
+				if association.SubnetId != nil && *association.SubnetId == *subnet.SubnetId {
+					subnetRouteTable = i
+				}
+			}
+		}
+
+		// Subnets without an explicit association use the main route table.
+		if subnetRouteTable == -1 {
+			subnetRouteTable = mainRouteTable
+		}
+
+		if subnetRouteTable == -1 {
+			result.hasNat = false
+			continue
+		}
+
+		routeTable := routeTables[subnetRouteTable]
+
+		if !slices.Contains(result.routeTableIds, *routeTable.RouteTableId) {
+			result.routeTableIds = append(result.routeTableIds, *routeTable.RouteTableId)
+		}
+
+		hasNat := false
+
+		for _, route := range routeTable.Routes {
+			if route.DestinationCidrBlock != nil && *route.DestinationCidrBlock == "0.0.0.0/0" &&
+				(route.NatGatewayId != nil || route.TransitGatewayId != nil || route.InstanceId != nil || route.NetworkInterfaceId != nil) {
+				hasNat = true
+			}
+		}
+
+		result.hasNat = result.hasNat && hasNat
+	}
+
+	return result, nil
+}
+
+func verifyVpcEndpoints(manifest *manifest.Manifest, vpc *existingVpc) error {
+	if !manifest.VPC.Enabled() {
+		return nil
+	}
+
+	hasNat := manifest.VPC.UsesNat()
+
+	if !manifest.VPC.Create {
+		if vpc == nil {
+			return nil
+		}
+
+		hasNat = vpc.hasNat
+	}
+
+	if hasNat {
+		return nil
+	}
+
+	var missing []string
+
+	for _, endpoint := range getRequiredVpcEndpoints(manifest) {
+		if !slices.Contains(manifest.VPC.Endpoints, endpoint) {
+			missing = append(missing, endpoint)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("the functions run in a VPC without a NAT gateway and can't reach %s. Add them to `vpc.endpoints` or route the subnets through a NAT gateway", strings.Join(missing, ", "))
+}
+
+func getRequiredVpcEndpoints(manifest *manifest.Manifest) []string {
+	result := []string{"kms"}
+
+	if len(manifest.Queue) > 0 {
+		result = append(result, "sqs")
+	}
+
+	// The warmer invokes the HTTP function from itself.
+	if !manifest.UsesProvisionedConcurrency() && manifest.WarmContainers() > 0 {
+		result = append(result, "lambda")
+	}
+
+	if manifest.UsesDynamoDbCache() || manifest.Websocket != nil {
+		result = append(result, "dynamodb")
+	}
+
+	if manifest.Storage != nil {
+		result = append(result, "s3")
+	}
+
+	if manifest.Database != nil {
+		result = append(result, "secretsmanager")
+	}
+
+	return result
+}
@@ -0,0 +1,131 @@
+package provisioner
+
+import (
+	"hover/utils/manifest"
+)
+
+const websocketStageName = "live"
+
+func websocketApi(websocketLambdaResourceName string, websocketLambdaAliasResourceName string, manifest *manifest.Manifest) map[string]any {
+	result := map[string]any{
+		"WebsocketApi": map[string]any{
+			"Type": "AWS::ApiGatewayV2::Api",
+			"Properties": map[string]any{
+				"Name":                     manifest.Name + "-websocket",
+				"ProtocolType":             "WEBSOCKET",
+				"RouteSelectionExpression": "$request.body.action",
+			},
+		},
+		"WebsocketLambdaIntegration": map[string]any{
+			"Type": "AWS::ApiGatewayV2::Integration",
+			"DependsOn": []any{
+				websocketLambdaAliasResourceName,
+			},
+			"Properties": map[string]any{
+				"ApiId": map[string]any{
+					"Ref": "WebsocketApi",
+				},
+				"IntegrationType": "AWS_PROXY",
+				"IntegrationUri": map[string]any{
+					"Fn::Sub": []any{
+						"arn:aws:apigateway:${AWS::Region}:lambda:path/2015-03-31/functions/${functionArn}:live/invocations",
+						map[string]any{
+							"functionArn": map[string]any{
+								"Fn::GetAtt": []any{
+									websocketLambdaResourceName,
+									"Arn",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"WebsocketStage": map[string]any{
+			"Type": "AWS::ApiGatewayV2::Stage",
+			"Properties": map[string]any{
+				"ApiId": map[string]any{
+					"Ref": "WebsocketApi",
+				},
+				"StageName":  websocketStageName,
+				"AutoDeploy": true,
+			},
+		},
+		"WebsocketInvokePermission": map[string]any{
+			"Type": "AWS::Lambda::Permission",
+			"DependsOn": []any{
+				websocketLambdaAliasResourceName,
+			},
+			"Properties": map[string]any{
+				"Action": "lambda:InvokeFunction",
+				"FunctionName": map[string]any{
+					"Fn::Join": []any{
+						":",
+						[]any{
+							map[string]any{
+								"Ref": websocketLambdaResourceName,
+							},
+							"live",
+						},
+					},
+				},
+				"Principal": "apigateway.amazonaws.com",
+				"SourceArn": map[string]any{
+					"Fn::Sub": "arn:aws:execute-api:${AWS::Region}:${AWS::AccountId}:${WebsocketApi}/*",
+				},
+			},
+		},
+		"WebsocketConnectionsTable": map[string]any{
+			"Type": "AWS::DynamoDB::Table",
+			"Properties": map[string]any{
+				"TableName":   manifest.Name + "-websocket-connections",
+				"BillingMode": "PAY_PER_REQUEST",
+				"AttributeDefinitions": []any{
+					map[string]any{
+						"AttributeName": "connectionId",
+						"AttributeType": "S",
+					},
+				},
+				"KeySchema": []any{
+					map[string]any{
+						"AttributeName": "connectionId",
+						"KeyType":       "HASH",
+					},
+				},
+				"TimeToLiveSpecification": map[string]any{
+					"AttributeName": "expires_at",
+					"Enabled":       true,
+				},
+			},
+		},
+	}
+
+	for _, routeKey := range []string{"$connect", "$disconnect", "$default"} {
+		result["Websocket"+logicalId(routeKey)+"Route"] = map[string]any{
+			"Type": "AWS::ApiGatewayV2::Route",
+			"DependsOn": []any{
+				"WebsocketLambdaIntegration",
+			},
+			"Properties": map[string]any{
+				"ApiId": map[string]any{
+					"Ref": "WebsocketApi",
+				},
+				"RouteKey":          routeKey,
+				"AuthorizationType": "NONE",
+				"Target": map[string]any{
+					"Fn::Join": []any{
+						"/",
+						[]any{
+							"integrations",
+							map[string]any{
+								"Ref": "WebsocketLambdaIntegration",
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,192 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"regexp"
+	"strings"
+)
+
+var domainPattern = regexp.MustCompile(`^(\*\.)?([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z][a-z0-9-]{0,61}[a-z0-9]$`)
+
+type Domain struct {
+	Name          string `yaml:"name" json:"name"`
+	Certificate   string `yaml:"certificate" json:"certificate"`
+	RedirectToWww bool   `yaml:"redirect-to-www" json:"redirect-to-www"`
+	Canonical     bool   `yaml:"canonical" json:"canonical"`
+}
+
+type Domains []Domain
+
+func (domains *Domains) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*domains = parseDomainsString(value.Value)
+
+		return nil
+	}
+
+	var entries []Domain
+
+	err := value.Decode(&entries)
+	if err != nil {
+		return err
+	}
+
+	*domains = entries
+
+	return nil
+}
+
+func (domains *Domains) UnmarshalJSON(data []byte) error {
+	var domainsString string
+
+	if json.Unmarshal(data, &domainsString) == nil {
+		*domains = parseDomainsString(domainsString)
+
+		return nil
+	}
+
+	var entries []Domain
+
+	err := json.Unmarshal(data, &entries)
+	if err != nil {
+		return err
+	}
+
+	*domains = entries
+
+	return nil
+}
+
+func (domain *Domain) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*domain = Domain{Name: strings.ToLower(value.Value)}
+
+		return nil
+	}
+
+	type plainDomain Domain
+
+	err := value.Decode((*plainDomain)(domain))
+	domain.Name = strings.ToLower(domain.Name)
+
+	return err
+}
+
+func (domain *Domain) UnmarshalJSON(data []byte) error {
+	var name string
+
+	if json.Unmarshal(data, &name) == nil {
+		*domain = Domain{Name: strings.ToLower(name)}
+
+		return nil
+	}
+
+	type plainDomain Domain
+
+	err := json.Unmarshal(data, (*plainDomain)(domain))
+	domain.Name = strings.ToLower(domain.Name)
+
+	return err
+}
+
+func parseDomainsString(value string) Domains {
+	var domains Domains
+
+	for _, name := range strings.Split(strings.ReplaceAll(value, " ", ""), ",") {
+		if name != "" {
+			domains = append(domains, Domain{Name: strings.ToLower(name)})
+		}
+	}
+
+	return domains
+}
+
+func (domains Domains) Names() []string {
+	var names []string
+
+	for _, domain := range domains {
+		names = append(names, domain.Name)
+	}
+
+	return names
+}
+
+func (domains Domains) Canonical() *Domain {
+	for i := range domains {
+		if domains[i].Canonical {
+			return &domains[i]
+		}
+	}
+
+	return nil
+}
+
+func (domains Domains) Redirects() map[string]string {
+	redirects := map[string]string{}
+	canonical := domains.Canonical()
+
+	for _, domain := range domains {
+		if strings.HasPrefix(domain.Name, "*.") {
+			continue
+		}
+
+		if canonical != nil && domain.Name != canonical.Name {
+			redirects[domain.Name] = canonical.Name
+		} else if canonical == nil && domain.RedirectToWww {
+			redirects[domain.Name] = "www." + domain.Name
+		}
+	}
+
+	return redirects
+}
+
+func (domains Domains) Validate(defaultCertificate string) error {
+	names := map[string]bool{}
+	canonicalDomains := 0
+
+	for _, domain := range domains {
+		if !domainPattern.MatchString(domain.Name) {
+			return fmt.Errorf("`%s` is not a valid domain name", domain.Name)
+		}
+
+		if names[domain.Name] {
+			return fmt.Errorf("the domain `%s` is defined more than once", domain.Name)
+		}
+
+		names[domain.Name] = true
+
+		if domain.Certificate == "" && defaultCertificate == "" {
+			return fmt.Errorf("the domain `%s` has no certificate. Set `http.certificate` or a certificate for the domain", domain.Name)
+		}
+
+		if domain.Canonical {
+			canonicalDomains++
+
+			if strings.HasPrefix(domain.Name, "*.") {
+				return fmt.Errorf("the wildcard domain `%s` cannot be canonical", domain.Name)
+			}
+		}
+
+		if domain.RedirectToWww && (strings.HasPrefix(domain.Name, "*.") || strings.HasPrefix(domain.Name, "www.")) {
+			return fmt.Errorf("the domain `%s` cannot redirect to www", domain.Name)
+		}
+
+		if domain.RedirectToWww && domain.Canonical {
+			return fmt.Errorf("the canonical domain `%s` cannot redirect to www", domain.Name)
+		}
+	}
+
+	if canonicalDomains > 1 {
+		return fmt.Errorf("only one domain can be canonical")
+	}
+
+	for _, domain := range domains {
+		if domain.RedirectToWww && !names["www."+domain.Name] && !names["*."+domain.Name] {
+			return fmt.Errorf("the domain `%s` redirects to `www.%s` which isn't in the list of domains", domain.Name, domain.Name)
+		}
+	}
+
+	return nil
+}
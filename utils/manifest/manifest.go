@@ -2,21 +2,355 @@ package manifest
 
 import (
 	"fmt"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 	"gopkg.in/yaml.v3"
 	"hover/utils"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 )
 
 type Queue struct {
+	Memory                         int                   `yaml:"memory" json:"memory"`
+	Timeout                        int                   `yaml:"timeout" json:"timeout"`
+	Concurrency                    int                   `yaml:"concurrency" json:"concurrency"`
+	ProvisionedConcurrency         int                   `yaml:"provisioned-concurrency" json:"provisioned-concurrency"`
+	ProvisionedConcurrencySchedule []ConcurrencySchedule `yaml:"provisioned-concurrency-schedule" json:"provisioned-concurrency-schedule"`
+	Tries                          int                   `yaml:"tries" json:"tries"`
+	Backoff                        string                `yaml:"backoff" json:"backoff"`
+	DeadLetterQueue                string                `yaml:"dead-letter-queue" json:"dead-letter-queue"`
+	Fifo                           bool                  `yaml:"fifo" json:"fifo"`
+	ContentBasedDeduplication      *bool                 `yaml:"content-based-deduplication" json:"content-based-deduplication"`
+	Throughput                     string                `yaml:"throughput" json:"throughput"`
+	BatchSize                      int                   `yaml:"batch-size" json:"batch-size"`
+	BatchingWindow                 int                   `yaml:"batching-window" json:"batching-window"`
+	MaximumConcurrency             int                   `yaml:"maximum-concurrency" json:"maximum-concurrency"`
+	Queues                         []SqsQueue            `yaml:"queues" json:"queues"`
+}
+
+func (manifest *Manifest) validateQueueNames() error {
+	type sqsQueue struct {
+		name        string
+		description string
+	}
+
+	owners := map[string]string{}
+
+	workerNames := maps.Keys(manifest.Queue)
+	slices.Sort(workerNames)
+
+	for _, workerName := range workerNames {
+		queue := manifest.Queue[workerName]
+
+		var queues []sqsQueue
+
+		for _, queueName := range queue.QueueNames() {
+			queues = append(queues, sqsQueue{queueName, fmt.Sprintf("the `%s` queue", queueName)})
+
+			if queue.DeadLetterQueueMode() == DeadLetterQueuePerQueue {
+				queues = append(queues, sqsQueue{queueName + "-dlq", fmt.Sprintf("the dead-letter queue of the `%s` queue", queueName)})
+			}
+		}
+
+		if queue.DeadLetterQueueMode() == DeadLetterQueueShared {
+			queues = append(queues, sqsQueue{workerName + "-dlq", fmt.Sprintf("the dead-letter queue of the `%s` queue worker", workerName)})
+		}
+
+		for _, sqsQueue := range queues {
+			sqsName := sqsQueue.name
+			if queue.Fifo {
+				sqsName += ".fifo"
+			}
+
+			if owner, ok := owners[sqsName]; ok {
+				return fmt.Errorf("%s and %s have the same name `%s`", owner, sqsQueue.description, sqsQueue.name)
+			}
+
+			owners[sqsName] = sqsQueue.description
+		}
+	}
+
+	return nil
+}
+
+const CacheDynamoDb = "dynamodb"
+
+const (
+	ThroughputStandard = "standard"
+	ThroughputHigh     = "high"
+)
+
+func (queue Queue) QueueNames() []string {
+	var names []string
+
+	for _, sqsQueue := range queue.Queues {
+		names = append(names, sqsQueue.Name)
+	}
+
+	return names
+}
+
+func (queue Queue) validateBatching() error {
+	maxBatchSize := 10000
+	if queue.Fifo {
+		maxBatchSize = 10
+	}
+
+	if queue.BatchSize < 0 || queue.BatchSize > maxBatchSize {
+		return fmt.Errorf("`batch-size` must be between 1 and %d", maxBatchSize)
+	}
+
+	if queue.BatchingWindow < 0 || queue.BatchingWindow > 300 {
+		return fmt.Errorf("`batching-window` must be between 0 and 300 seconds")
+	}
+
+	if queue.Fifo && queue.BatchingWindow > 0 {
+		return fmt.Errorf("`batching-window` isn't supported by FIFO queues")
+	}
+
+	if queue.BatchSize > 10 && queue.BatchingWindow == 0 {
+		return fmt.Errorf("`batching-window` must be set when `batch-size` is greater than 10")
+	}
+
+	if queue.MaximumConcurrency != 0 && (queue.MaximumConcurrency < 2 || queue.MaximumConcurrency > 1000) {
+		return fmt.Errorf("`maximum-concurrency` must be between 2 and 1000")
+	}
+
+	return nil
+}
+
+func (queue Queue) UsesContentBasedDeduplication() bool {
+	return queue.ContentBasedDeduplication == nil || *queue.ContentBasedDeduplication
+}
+
+const (
+	DeadLetterQueuePerQueue = "per-queue"
+	DeadLetterQueueShared   = "shared"
+	DeadLetterQueueNone     = "none"
+)
+
+// Messages can only move to a dead-letter queue after `tries` receives.
+func (queue Queue) DeadLetterQueueMode() string {
+	if queue.Tries == 0 {
+		return DeadLetterQueueNone
+	}
+
+	if queue.DeadLetterQueue == "" {
+		return DeadLetterQueuePerQueue
+	}
+
+	return queue.DeadLetterQueue
+}
+
+type ConcurrencySchedule struct {
+	Cron        string `yaml:"cron" json:"cron"`
+	Timezone    string `yaml:"timezone" json:"timezone"`
+	Concurrency int    `yaml:"concurrency" json:"concurrency"`
+}
+
+type Throttle struct {
+	Burst int     `yaml:"burst" json:"burst"`
+	Rate  float64 `yaml:"rate" json:"rate"`
+}
+
+func (throttle Throttle) Enabled() bool {
+	return throttle.Burst != 0 || throttle.Rate != 0
+}
+
+type AccessLog struct {
+	Enabled bool   `yaml:"enabled" json:"enabled"`
+	Format  string `yaml:"format" json:"format"`
+}
+
+type Route struct {
+	Route    string   `yaml:"route" json:"route"`
+	Throttle Throttle `yaml:"throttle" json:"throttle"`
+}
+
+type HttpFunction struct {
 	Memory      int      `yaml:"memory" json:"memory"`
 	Timeout     int      `yaml:"timeout" json:"timeout"`
 	Concurrency int      `yaml:"concurrency" json:"concurrency"`
-	Tries       int      `yaml:"tries" json:"tries"`
-	Backoff     string   `yaml:"backoff" json:"backoff"`
-	Queues      []string `yaml:"queues" json:"queues"`
+	Paths       []string `yaml:"paths" json:"paths"`
+}
+
+type Canary struct {
+	ErrorThreshold float64 `yaml:"error-threshold" json:"error-threshold"`
+}
+
+type Schedule struct {
+	Expression string         `yaml:"expression" json:"expression"`
+	Command    string         `yaml:"command" json:"command"`
+	Timezone   string         `yaml:"timezone" json:"timezone"`
+	Input      map[string]any `yaml:"input" json:"input"`
+	Enabled    *bool          `yaml:"enabled" json:"enabled"`
+}
+
+func (schedule Schedule) IsEnabled() bool {
+	return schedule.Enabled == nil || *schedule.Enabled
+}
+
+const (
+	TriggerS3          = "s3"
+	TriggerSns         = "sns"
+	TriggerEventBridge = "eventbridge"
+)
+
+type Trigger struct {
+	Type    string         `yaml:"type" json:"type"`
+	Command string         `yaml:"command" json:"command"`
+	Bucket  string         `yaml:"bucket" json:"bucket"`
+	Events  []string       `yaml:"events" json:"events"`
+	Prefix  string         `yaml:"prefix" json:"prefix"`
+	Suffix  string         `yaml:"suffix" json:"suffix"`
+	Topic   string         `yaml:"topic" json:"topic"`
+	Pattern map[string]any `yaml:"pattern" json:"pattern"`
+	Bus     string         `yaml:"bus" json:"bus"`
+}
+
+func (trigger Trigger) Validate() error {
+	if strings.TrimSpace(trigger.Command) == "" {
+		return fmt.Errorf("no command is defined")
+	}
+
+	switch trigger.Type {
+	case TriggerS3:
+		if trigger.Bucket == "" {
+			return fmt.Errorf("S3 triggers require a `bucket`")
+		}
+	case TriggerSns:
+		if trigger.Topic == "" {
+			return fmt.Errorf("SNS triggers require a `topic`")
+		}
+	case TriggerEventBridge:
+		if len(trigger.Pattern) == 0 {
+			return fmt.Errorf("EventBridge triggers require a `pattern`")
+		}
+	default:
+		return fmt.Errorf("unknown type `%s`. Expected `%s`, `%s` or `%s`", trigger.Type, TriggerS3, TriggerSns, TriggerEventBridge)
+	}
+
+	return nil
 }
 
+type HealthCheck struct {
+	Path     string `yaml:"path" json:"path"`
+	Status   int    `yaml:"status" json:"status"`
+	Body     string `yaml:"body" json:"body"`
+	Attempts int    `yaml:"attempts" json:"attempts"`
+}
+
+const (
+	DatabaseMysql    = "mysql"
+	DatabasePostgres = "postgres"
+)
+
+type Database struct {
+	Engine      string  `yaml:"engine" json:"engine"`
+	Version     string  `yaml:"version" json:"version"`
+	Name        string  `yaml:"name" json:"name"`
+	Username    string  `yaml:"username" json:"username"`
+	MinCapacity float64 `yaml:"min-capacity" json:"min-capacity"`
+	MaxCapacity float64 `yaml:"max-capacity" json:"max-capacity"`
+}
+
+func (database Database) Validate() error {
+	if database.Engine != DatabaseMysql && database.Engine != DatabasePostgres {
+		return fmt.Errorf("invalid `engine`. Expected `%s` or `%s`", DatabaseMysql, DatabasePostgres)
+	}
+
+	for _, capacity := range []float64{database.MinCapacity, database.MaxCapacity} {
+		if capacity != 0 && (capacity < 0.5 || capacity > 128 || capacity*2 != float64(int(capacity*2))) {
+			return fmt.Errorf("capacities must be between 0.5 and 128, in steps of 0.5")
+		}
+	}
+
+	if database.MaxCapacity != 0 && database.MaxCapacity < database.MinCapacity {
+		return fmt.Errorf("`max-capacity` must be greater than `min-capacity`")
+	}
+
+	return nil
+}
+
+type Redis struct {
+	Version    string `yaml:"version" json:"version"`
+	MaxStorage int    `yaml:"max-storage" json:"max-storage"`
+	MaxEcpu    int    `yaml:"max-ecpu" json:"max-ecpu"`
+}
+
+func (redis Redis) Validate() error {
+	if redis.MaxStorage != 0 && (redis.MaxStorage < 1 || redis.MaxStorage > 5000) {
+		return fmt.Errorf("`max-storage` must be between 1 and 5000 GB")
+	}
+
+	if redis.MaxEcpu != 0 && (redis.MaxEcpu < 1000 || redis.MaxEcpu > 15000000) {
+		return fmt.Errorf("`max-ecpu` must be between 1000 and 15000000 ECPUs per second")
+	}
+
+	return nil
+}
+
+type Storage struct {
+	CorsOrigins                 []string     `yaml:"cors-origins" json:"cors-origins"`
+	NoncurrentVersionExpiration int          `yaml:"noncurrent-version-expiration" json:"noncurrent-version-expiration"`
+	Expirations                 []Expiration `yaml:"expirations" json:"expirations"`
+}
+
+type Expiration struct {
+	Prefix string `yaml:"prefix" json:"prefix"`
+	Days   int    `yaml:"days" json:"days"`
+}
+
+type Websocket struct {
+	Memory      int `yaml:"memory" json:"memory"`
+	Timeout     int `yaml:"timeout" json:"timeout"`
+	Concurrency int `yaml:"concurrency" json:"concurrency"`
+}
+
+type Firewall struct {
+	ManagedRules []string            `yaml:"managed-rules" json:"managed-rules"`
+	RateLimits   []FirewallRateLimit `yaml:"rate-limits" json:"rate-limits"`
+	AllowIPs     []string            `yaml:"allow-ips" json:"allow-ips"`
+	DenyIPs      []string            `yaml:"deny-ips" json:"deny-ips"`
+}
+
+type FirewallRateLimit struct {
+	Limit int    `yaml:"limit" json:"limit"`
+	Path  string `yaml:"path" json:"path"`
+}
+
+func (firewall Firewall) Enabled() bool {
+	return len(firewall.ManagedRules) > 0 ||
+		len(firewall.RateLimits) > 0 ||
+		len(firewall.AllowIPs) > 0 ||
+		len(firewall.DenyIPs) > 0
+}
+
+func (firewall Firewall) Validate() error {
+	for _, rateLimit := range firewall.RateLimits {
+		// WAF rejects rate-based rules below 100 requests per 5 minutes.
+		if rateLimit.Limit < 100 {
+			return fmt.Errorf("the `limit` of rate limits must be at least 100, got %d", rateLimit.Limit)
+		}
+	}
+
+	return nil
+}
+
+var (
+	routePattern        = regexp.MustCompile(`^(ANY|GET|HEAD|OPTIONS|POST|PUT|PATCH|DELETE) /\S*$`)
+	functionNamePattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	functionPathPattern = regexp.MustCompile(`^/[^*\s]*(/\*)?$`)
+	schedulePattern     = regexp.MustCompile(`^(cron|rate|at)\(.+\)$`)
+)
+
+const (
+	GatewayApiGateway  = "api-gateway"
+	GatewayFunctionUrl = "function-url"
+)
+
 type Manifest struct {
 	Name           string            `yaml:"name" json:"name"`
 	AwsProfile     string            `yaml:"aws-profile" json:"aws-profile"`
@@ -28,24 +362,44 @@ type Manifest struct {
 		LambdaRole string `yaml:"lambda-role" json:"lambda-role"`
 		StackRole  string `yaml:"stack-role" json:"stack-role"`
 	} `yaml:"auth" json:"auth"`
-	VPC struct {
-		SecurityGroups []string `yaml:"security-groups" json:"security-groups"`
-		Subnets        []string `yaml:"subnets" json:"subnets"`
-	} `yaml:"vpc" json:"vpc"`
+	VPC  Vpc `yaml:"vpc" json:"vpc"`
 	HTTP struct {
-		Memory      int    `yaml:"memory" json:"memory"`
-		Timeout     int    `yaml:"timeout" json:"timeout"`
-		Warm        int    `yaml:"warm" json:"warm"`
-		Concurrency int    `yaml:"concurrency" json:"concurrency"`
-		Domains     string `yaml:"domains" json:"domains"`
-		Certificate string `yaml:"certificate" json:"certificate"`
+		Memory                         int                     `yaml:"memory" json:"memory"`
+		Timeout                        int                     `yaml:"timeout" json:"timeout"`
+		Warm                           *int                    `yaml:"warm" json:"warm"`
+		WarmRate                       int                     `yaml:"warm-rate" json:"warm-rate"`
+		WarmWindow                     WarmWindow              `yaml:"warm-window" json:"warm-window"`
+		Concurrency                    int                     `yaml:"concurrency" json:"concurrency"`
+		ProvisionedConcurrency         int                     `yaml:"provisioned-concurrency" json:"provisioned-concurrency"`
+		ProvisionedConcurrencySchedule []ConcurrencySchedule   `yaml:"provisioned-concurrency-schedule" json:"provisioned-concurrency-schedule"`
+		Gateway                        string                  `yaml:"gateway" json:"gateway"`
+		Throttle                       Throttle                `yaml:"throttle" json:"throttle"`
+		AccessLog                      AccessLog               `yaml:"access-log" json:"access-log"`
+		Routes                         []Route                 `yaml:"routes" json:"routes"`
+		Functions                      map[string]HttpFunction `yaml:"functions" json:"functions"`
+		Canary                         Canary                  `yaml:"canary" json:"canary"`
+		Domains                        Domains                 `yaml:"domains" json:"domains"`
+		Certificate                    string                  `yaml:"certificate" json:"certificate"`
+		HostedZone                     string                  `yaml:"hosted-zone" json:"hosted-zone"`
+		ErrorPage                      string                  `yaml:"error-page" json:"error-page"`
+		MaintenancePage                string                  `yaml:"maintenance-page" json:"maintenance-page"`
 	} `yaml:"http" json:"http"`
 	Cli struct {
-		Memory      int `yaml:"memory" json:"memory"`
-		Timeout     int `yaml:"timeout" json:"timeout"`
-		Concurrency int `yaml:"concurrency" json:"concurrency"`
+		Memory      int   `yaml:"memory" json:"memory"`
+		Timeout     int   `yaml:"timeout" json:"timeout"`
+		Concurrency int   `yaml:"concurrency" json:"concurrency"`
+		Scheduler   *bool `yaml:"scheduler" json:"scheduler"`
 	} `yaml:"cli" json:"cli"`
+	Schedules    []Schedule       `yaml:"schedules" json:"schedules"`
+	Triggers     []Trigger        `yaml:"triggers" json:"triggers"`
 	Queue        map[string]Queue `yaml:"queue" json:"queue"`
+	Websocket    *Websocket       `yaml:"websocket" json:"websocket"`
+	Cache        string           `yaml:"cache" json:"cache"`
+	Storage      *Storage         `yaml:"storage" json:"storage"`
+	Database     *Database        `yaml:"database" json:"database"`
+	Redis        *Redis           `yaml:"redis" json:"redis"`
+	HealthCheck  HealthCheck      `yaml:"health-check" json:"health-check"`
+	Firewall     Firewall         `yaml:"firewall" json:"firewall"`
 	BuildDetails struct {
 		Id   string `yaml:"id" json:"id"`
 		Hash string `yaml:"hash" json:"hash"`
@@ -53,6 +407,210 @@ type Manifest struct {
 	} `yaml:"build_details" json:"build_details"`
 }
 
+func (manifest *Manifest) DomainNames() []string {
+	return manifest.HTTP.Domains.Names()
+}
+
+func (manifest *Manifest) UsesScheduler() bool {
+	return manifest.Cli.Scheduler == nil || *manifest.Cli.Scheduler
+}
+
+func (manifest *Manifest) WarmContainers() int {
+	if manifest.HTTP.Warm == nil {
+		return 1
+	}
+
+	return *manifest.HTTP.Warm
+}
+
+func (manifest *Manifest) WarmRate() int {
+	if manifest.HTTP.WarmRate == 0 {
+		return 5
+	}
+
+	return manifest.HTTP.WarmRate
+}
+
+func (manifest *Manifest) UsesProvisionedConcurrency() bool {
+	return manifest.HTTP.ProvisionedConcurrency > 0 || len(manifest.HTTP.ProvisionedConcurrencySchedule) > 0
+}
+
+func (manifest *Manifest) UsesFunctionUrl() bool {
+	return manifest.HTTP.Gateway == GatewayFunctionUrl
+}
+
+func (manifest *Manifest) UsesDynamoDbCache() bool {
+	return manifest.Cache == CacheDynamoDb
+}
+
+func (manifest *Manifest) Validate() error {
+	err := manifest.HTTP.Domains.Validate(manifest.HTTP.Certificate)
+	if err != nil {
+		return fmt.Errorf("invalid `http.domains` in the manifest file. Error: %w", err)
+	}
+
+	if manifest.Cache != "" && manifest.Cache != CacheDynamoDb {
+		return fmt.Errorf("invalid `cache` in the manifest file. Expected `%s`", CacheDynamoDb)
+	}
+
+	if manifest.Storage != nil {
+		if manifest.Storage.NoncurrentVersionExpiration < 0 {
+			return fmt.Errorf("invalid `storage.noncurrent-version-expiration` in the manifest file. It must be a number of days")
+		}
+
+		for i, expiration := range manifest.Storage.Expirations {
+			if expiration.Days < 1 {
+				return fmt.Errorf("expiration #%d in `storage.expirations` must expire objects after at least 1 day", i+1)
+			}
+		}
+	}
+
+	err = manifest.VPC.Validate()
+	if err != nil {
+		return fmt.Errorf("invalid `vpc` in the manifest file. Error: %w", err)
+	}
+
+	if manifest.Database != nil {
+		err := manifest.Database.Validate()
+		if err != nil {
+			return fmt.Errorf("invalid `database` in the manifest file. Error: %w", err)
+		}
+
+		if !manifest.VPC.HasMultipleSubnets() {
+			return fmt.Errorf("`database` requires `vpc.create`, or `vpc.security-groups` and at least 2 `vpc.subnets`")
+		}
+	}
+
+	if manifest.Redis != nil {
+		err := manifest.Redis.Validate()
+		if err != nil {
+			return fmt.Errorf("invalid `redis` in the manifest file. Error: %w", err)
+		}
+
+		if !manifest.VPC.HasMultipleSubnets() {
+			return fmt.Errorf("`redis` requires `vpc.create`, or `vpc.security-groups` and at least 2 `vpc.subnets`")
+		}
+	}
+
+	if manifest.HTTP.Gateway != "" && manifest.HTTP.Gateway != GatewayApiGateway && manifest.HTTP.Gateway != GatewayFunctionUrl {
+		return fmt.Errorf("invalid `http.gateway` in the manifest file. Expected `%s` or `%s`", GatewayApiGateway, GatewayFunctionUrl)
+	}
+
+	for i, schedule := range manifest.Schedules {
+		if !schedulePattern.MatchString(schedule.Expression) {
+			return fmt.Errorf("invalid expression `%s` of schedule #%d in `schedules`. Use a cron, rate or at expression", schedule.Expression, i+1)
+		}
+
+		if strings.TrimSpace(schedule.Command) == "" {
+			return fmt.Errorf("schedule #%d in `schedules` has no command", i+1)
+		}
+	}
+
+	for i, trigger := range manifest.Triggers {
+		err := trigger.Validate()
+		if err != nil {
+			return fmt.Errorf("invalid trigger #%d in `triggers`. Error: %w", i+1, err)
+		}
+	}
+
+	err = manifest.HTTP.WarmWindow.Validate()
+	if err != nil {
+		return fmt.Errorf("invalid `http.warm-window` in the manifest file. Error: %w", err)
+	}
+
+	if manifest.WarmContainers() < 0 {
+		return fmt.Errorf("`http.warm` must be a positive number of containers, or 0 to disable warming")
+	}
+
+	if manifest.HTTP.WarmRate < 0 {
+		return fmt.Errorf("`http.warm-rate` must be a positive number of minutes")
+	}
+
+	if manifest.HTTP.WarmWindow.Enabled() && manifest.WarmRate() >= 60 {
+		return fmt.Errorf("`http.warm-rate` must be less than 60 minutes when `http.warm-window` is set")
+	}
+
+	if manifest.UsesFunctionUrl() && (manifest.HTTP.Throttle.Enabled() || manifest.HTTP.AccessLog.Enabled || len(manifest.HTTP.Routes) > 0) {
+		return fmt.Errorf("`http.throttle`, `http.access-log` and `http.routes` are only supported by the `%s` gateway", GatewayApiGateway)
+	}
+
+	routes := map[string]bool{}
+
+	for _, route := range manifest.HTTP.Routes {
+		if !routePattern.MatchString(route.Route) {
+			return fmt.Errorf("invalid route `%s` in `http.routes`. Routes must look like `POST /login`", route.Route)
+		}
+
+		if routes[route.Route] {
+			return fmt.Errorf("the route `%s` is defined more than once in `http.routes`", route.Route)
+		}
+
+		routes[route.Route] = true
+	}
+
+	for name, queue := range manifest.Queue {
+		if queue.DeadLetterQueue != "" && queue.DeadLetterQueue != DeadLetterQueuePerQueue && queue.DeadLetterQueue != DeadLetterQueueShared && queue.DeadLetterQueue != DeadLetterQueueNone {
+			return fmt.Errorf("invalid `dead-letter-queue` of the `%s` queue worker. Expected `%s`, `%s` or `%s`", name, DeadLetterQueuePerQueue, DeadLetterQueueShared, DeadLetterQueueNone)
+		}
+
+		if queue.Throughput != "" && queue.Throughput != ThroughputStandard && queue.Throughput != ThroughputHigh {
+			return fmt.Errorf("invalid `throughput` of the `%s` queue worker. Expected `%s` or `%s`", name, ThroughputStandard, ThroughputHigh)
+		}
+
+		if !queue.Fifo && (queue.Throughput != "" || queue.ContentBasedDeduplication != nil) {
+			return fmt.Errorf("`throughput` and `content-based-deduplication` of the `%s` queue worker are only supported by FIFO queues", name)
+		}
+
+		err := queue.validateBatching()
+		if err != nil {
+			return fmt.Errorf("invalid batching options of the `%s` queue worker. Error: %w", name, err)
+		}
+
+		for _, sqsQueue := range queue.Queues {
+			err := sqsQueue.Validate()
+			if err != nil {
+				return fmt.Errorf("invalid `queues` of the `%s` queue worker. Error: %w", name, err)
+			}
+		}
+	}
+
+	err = manifest.validateQueueNames()
+	if err != nil {
+		return err
+	}
+
+	paths := map[string]bool{}
+
+	for name, function := range manifest.HTTP.Functions {
+		if !functionNamePattern.MatchString(name) {
+			return fmt.Errorf("invalid function name `%s` in `http.functions`. Use lowercase letters, numbers and dashes", name)
+		}
+
+		if len(function.Paths) == 0 {
+			return fmt.Errorf("the function `%s` in `http.functions` has no paths", name)
+		}
+
+		for _, path := range function.Paths {
+			if !functionPathPattern.MatchString(path) {
+				return fmt.Errorf("invalid path `%s` of the function `%s`. Paths must start with `/` and may only end with `/*`", path, name)
+			}
+
+			if paths[path] {
+				return fmt.Errorf("the path `%s` is used by more than one function in `http.functions`", path)
+			}
+
+			paths[path] = true
+		}
+	}
+
+	err = manifest.Firewall.Validate()
+	if err != nil {
+		return fmt.Errorf("invalid `firewall` in the manifest file. Error: %w", err)
+	}
+
+	return nil
+}
+
 func Get(alias string) (*Manifest, error) {
 	path := filepath.Join(utils.Path.Hover, alias+".yml")
 	file, err := os.ReadFile(path)
@@ -67,5 +625,10 @@ func Get(alias string) (*Manifest, error) {
 		return nil, fmt.Errorf("unable to parse the YAML manifest file. Error: %w", err)
 	}
 
+	err = manifest.Validate()
+	if err != nil {
+		return nil, err
+	}
+
 	return &manifest, nil
 }
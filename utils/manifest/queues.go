@@ -0,0 +1,81 @@
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	EncryptionSqs  = "sqs"
+	EncryptionKms  = "kms"
+	EncryptionNone = "none"
+)
+
+type SqsQueue struct {
+	Name        string `yaml:"name" json:"name"`
+	Retention   int    `yaml:"retention" json:"retention"`
+	Delay       int    `yaml:"delay" json:"delay"`
+	ReceiveWait int    `yaml:"receive-wait" json:"receive-wait"`
+	MaxSize     int    `yaml:"max-size" json:"max-size"`
+	Encryption  string `yaml:"encryption" json:"encryption"`
+	KmsKey      string `yaml:"kms-key" json:"kms-key"`
+}
+
+func (queue *SqsQueue) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*queue = SqsQueue{Name: value.Value}
+
+		return nil
+	}
+
+	type plainSqsQueue SqsQueue
+
+	return value.Decode((*plainSqsQueue)(queue))
+}
+
+func (queue *SqsQueue) UnmarshalJSON(data []byte) error {
+	var name string
+
+	if json.Unmarshal(data, &name) == nil {
+		*queue = SqsQueue{Name: name}
+
+		return nil
+	}
+
+	type plainSqsQueue SqsQueue
+
+	return json.Unmarshal(data, (*plainSqsQueue)(queue))
+}
+
+func (queue SqsQueue) Validate() error {
+	if queue.Name == "" {
+		return fmt.Errorf("a queue has no name")
+	}
+
+	if queue.Retention != 0 && (queue.Retention < 60 || queue.Retention > 1209600) {
+		return fmt.Errorf("the `retention` of the `%s` queue must be between 60 and 1209600 seconds", queue.Name)
+	}
+
+	if queue.Delay < 0 || queue.Delay > 900 {
+		return fmt.Errorf("the `delay` of the `%s` queue must be between 0 and 900 seconds", queue.Name)
+	}
+
+	if queue.ReceiveWait < 0 || queue.ReceiveWait > 20 {
+		return fmt.Errorf("the `receive-wait` of the `%s` queue must be between 0 and 20 seconds", queue.Name)
+	}
+
+	if queue.MaxSize != 0 && (queue.MaxSize < 1024 || queue.MaxSize > 262144) {
+		return fmt.Errorf("the `max-size` of the `%s` queue must be between 1024 and 262144 bytes", queue.Name)
+	}
+
+	if queue.Encryption != "" && queue.Encryption != EncryptionSqs && queue.Encryption != EncryptionKms && queue.Encryption != EncryptionNone {
+		return fmt.Errorf("invalid `encryption` of the `%s` queue. Expected `%s`, `%s` or `%s`", queue.Name, EncryptionSqs, EncryptionKms, EncryptionNone)
+	}
+
+	if queue.KmsKey != "" && queue.Encryption != EncryptionKms {
+		return fmt.Errorf("the `kms-key` of the `%s` queue requires `encryption` to be `%s`", queue.Name, EncryptionKms)
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package manifest
+
+import (
+	"fmt"
+	"golang.org/x/exp/slices"
+)
+
+const (
+	NatSingle = "single"
+	NatNone   = "none"
+)
+
+var VpcEndpoints = []string{"s3", "dynamodb", "sqs", "kms", "lambda", "logs", "ssm", "secretsmanager"}
+
+type Vpc struct {
+	SecurityGroups []string `yaml:"security-groups" json:"security-groups"`
+	Subnets        []string `yaml:"subnets" json:"subnets"`
+	Create         bool     `yaml:"create" json:"create"`
+	Nat            string   `yaml:"nat" json:"nat"`
+	Endpoints      []string `yaml:"endpoints" json:"endpoints"`
+}
+
+func (vpc Vpc) Enabled() bool {
+	return vpc.Create || len(vpc.Subnets) > 0
+}
+
+func (vpc Vpc) UsesNat() bool {
+	return vpc.Create && vpc.Nat != NatNone
+}
+
+// The availability zones of existing subnets aren't checked.
+func (vpc Vpc) HasMultipleSubnets() bool {
+	return vpc.Create || (len(vpc.Subnets) >= 2 && len(vpc.SecurityGroups) > 0)
+}
+
+func (vpc Vpc) Validate() error {
+	if vpc.Create && (len(vpc.Subnets) > 0 || len(vpc.SecurityGroups) > 0) {
+		return fmt.Errorf("`subnets` and `security-groups` can't be set when `create` is enabled")
+	}
+
+	if vpc.Nat != "" && !vpc.Create {
+		return fmt.Errorf("`nat` is only supported when `create` is enabled")
+	}
+
+	if vpc.Nat != "" && vpc.Nat != NatSingle && vpc.Nat != NatNone {
+		return fmt.Errorf("invalid `nat`. Expected `%s` or `%s`", NatSingle, NatNone)
+	}
+
+	if len(vpc.Endpoints) > 0 && !vpc.Enabled() {
+		return fmt.Errorf("`endpoints` require `create` or `subnets`")
+	}
+
+	for _, endpoint := range vpc.Endpoints {
+		if !slices.Contains(VpcEndpoints, endpoint) {
+			return fmt.Errorf("unknown endpoint `%s`. Expected one of %v", endpoint, VpcEndpoints)
+		}
+	}
+
+	return nil
+}
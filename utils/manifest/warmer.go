@@ -0,0 +1,108 @@
+package manifest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var weekDays = []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}
+
+type WarmWindow struct {
+	Hours string `yaml:"hours" json:"hours"`
+	Days  string `yaml:"days" json:"days"`
+}
+
+func (window WarmWindow) Enabled() bool {
+	return window.Hours != "" || window.Days != ""
+}
+
+func (window WarmWindow) ScheduleExpression(rate int) string {
+	hours := window.Hours
+	if hours == "" {
+		hours = "*"
+	}
+
+	days := window.Days
+	if days == "" {
+		days = "*"
+	}
+
+	return fmt.Sprintf("cron(0/%d %s ? * %s *)", rate, hours, days)
+}
+
+func (window WarmWindow) Contains(t time.Time) bool {
+	t = t.UTC()
+
+	hours, _ := parseWindowField(window.Hours, 0, 23, nil)
+	days, _ := parseWindowField(window.Days, 1, 7, weekDays)
+
+	return (hours == nil || hours[t.Hour()]) && (days == nil || days[int(t.Weekday())+1])
+}
+
+func (window WarmWindow) Validate() error {
+	_, err := parseWindowField(window.Hours, 0, 23, nil)
+	if err != nil {
+		return fmt.Errorf("invalid hours `%s`. Error: %w", window.Hours, err)
+	}
+
+	_, err = parseWindowField(window.Days, 1, 7, weekDays)
+	if err != nil {
+		return fmt.Errorf("invalid days `%s`. Error: %w", window.Days, err)
+	}
+
+	return nil
+}
+
+// An empty field or `*` returns nil, which matches any value.
+func parseWindowField(field string, min int, max int, names []string) (map[int]bool, error) {
+	if field == "" || field == "*" {
+		return nil, nil
+	}
+
+	parseValue := func(value string) (int, error) {
+		for i, name := range names {
+			if strings.EqualFold(value, name) {
+				return min + i, nil
+			}
+		}
+
+		number, err := strconv.Atoi(value)
+		if err != nil || number < min || number > max {
+			return 0, fmt.Errorf("`%s` is not between %d and %d", value, min, max)
+		}
+
+		return number, nil
+	}
+
+	values := map[int]bool{}
+
+	for _, part := range strings.Split(field, ",") {
+		bounds := strings.SplitN(strings.TrimSpace(part), "-", 2)
+
+		start, err := parseValue(bounds[0])
+		if err != nil {
+			return nil, err
+		}
+
+		end := start
+
+		if len(bounds) == 2 {
+			end, err = parseValue(bounds[1])
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if end < start {
+			return nil, fmt.Errorf("the range `%s` ends before it starts", part)
+		}
+
+		for value := start; value <= end; value++ {
+			values[value] = true
+		}
+	}
+
+	return values, nil
+}